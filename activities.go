@@ -0,0 +1,153 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActivityFilter restricts the activities returned by FetchActivities
+type ActivityFilter struct {
+	// UserID selects the activities assigned to a user, 0 selects the activities of all users
+	UserID int
+	// Types is a list of activity type key strings like "call" or "meeting"
+	Types []string
+	// Done selects done or undone activities, nil selects both
+	Done *bool
+	// StartDate and EndDate limit the due date of the activities, zero values leave the range open
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (f ActivityFilter) values() url.Values {
+	v := url.Values{}
+	v.Set("user_id", strconv.Itoa(f.UserID))
+	if len(f.Types) > 0 {
+		v.Set("type", strings.Join(f.Types, ","))
+	}
+	if f.Done != nil {
+		v.Set("done", strconv.Itoa(*intFlag(f.Done)))
+	}
+	if !f.StartDate.IsZero() {
+		v.Set("start_date", f.StartDate.Format("2006-01-02"))
+	}
+	if !f.EndDate.IsZero() {
+		v.Set("end_date", f.EndDate.Format("2006-01-02"))
+	}
+	return v
+}
+
+// intFlag converts an optional bool into the 0/1 representation used by the Pipedrive API
+func intFlag(b *bool) *int {
+	if b == nil {
+		return nil
+	}
+	i := 0
+	if *b {
+		i = 1
+	}
+	return &i
+}
+
+// FetchActivities streams all activities matching the filter
+func (pd *API) FetchActivities(filter ActivityFilter) (<-chan Activity, <-chan error) {
+	results := make(chan Activity)
+
+	values := filter.values()
+	generator := func(offset int) (string, error) {
+		values.Set("start", strconv.Itoa(offset))
+		return pd.Endpoints.Activities + "?" + values.Encode(), nil
+	}
+	worker := func(r GenericResponse) error {
+		result := []Activity{}
+		err := json.Unmarshal(r.Data, &result)
+		if err != nil {
+			return err
+		}
+		for _, a := range result {
+			results <- a
+		}
+		return nil
+	}
+	closer := func() { close(results) }
+	errs := pd.GenericStreamHelper(worker, generator, closer)
+
+	return results, errs
+}
+
+// ActivityParams holds the fields of an activity to be created or updated.
+// Zero values are left out.
+type ActivityParams struct {
+	Subject  string `json:"subject,omitempty"`
+	Type     string `json:"type,omitempty"`
+	DueDate  string `json:"due_date,omitempty"`
+	DueTime  string `json:"due_time,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	UserID   int    `json:"user_id,omitempty"`
+	DealID   int    `json:"deal_id,omitempty"`
+	PersonID int    `json:"person_id,omitempty"`
+	OrgID    int    `json:"org_id,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Done     *bool  `json:"-"`
+}
+
+// MarshalJSON encodes Done as 0 or 1 as expected by the API
+func (p ActivityParams) MarshalJSON() ([]byte, error) {
+	type params ActivityParams
+	return json.Marshal(struct {
+		params
+		Done *int `json:"done,omitempty"`
+	}{
+		params: params(p),
+		Done:   intFlag(p.Done),
+	})
+}
+
+// CreateActivity adds a new activity and returns it
+func (pd *API) CreateActivity(params ActivityParams) (Activity, error) {
+	var a Activity
+	err := pd.sendJSON(pd.postEndpoint, pd.Endpoints.Activities, params, &a)
+	return a, err
+}
+
+// UpdateActivity changes the given fields of an activity and returns the updated activity
+func (pd *API) UpdateActivity(id int, params ActivityParams) (Activity, error) {
+	var a Activity
+	err := pd.sendJSON(pd.putEndpoint, fmt.Sprintf(pd.Endpoints.Activity, id), params, &a)
+	return a, err
+}
+
+// MarkActivityDone marks the activity as done
+func (pd *API) MarkActivityDone(id int) (Activity, error) {
+	done := true
+	return pd.UpdateActivity(id, ActivityParams{Done: &done})
+}
+
+// DeleteActivity removes the activity with the given id
+func (pd *API) DeleteActivity(id int) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.Activity, id), nil, nil)
+}
+
+// ActivityType models the type of an activity like a call or a meeting
+type ActivityType struct {
+	ID           int    `json:"id"`
+	OrderNr      int    `json:"order_nr"`
+	Name         string `json:"name"`
+	KeyString    string `json:"key_string"`
+	IconKey      string `json:"icon_key"`
+	ActiveFlag   bool   `json:"active_flag"`
+	Color        string `json:"color"`
+	IsCustomFlag bool   `json:"is_custom_flag"`
+	AddTime      *Time  `json:"add_time"`
+	UpdateTime   *Time  `json:"update_time"`
+}
+
+// FetchActivityTypes returns all activity types
+func (pd *API) FetchActivityTypes() ([]ActivityType, error) {
+	var types []ActivityType
+	err := pd.getJSON(pd.Endpoints.ActivityTypes, &types)
+	return types, err
+}
@@ -0,0 +1,167 @@
+package pipedrive
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ProcessRules configures the checks done by CheckProcess
+type ProcessRules struct {
+	// RequiredStages lists the stage names every deal has to pass, by pipeline ID
+	RequiredStages map[int][]string
+	// OfferStage has to be passed by every won deal, empty disables the check
+	OfferStage string
+	// MaxBounces is the number of round trips between two stages tolerated, defaults to 1
+	MaxBounces int
+	// AllowBackward disables reporting of backward moves
+	AllowBackward bool
+}
+
+// ViolationKind names the rule a deal violated
+type ViolationKind string
+
+// Kinds of process violations
+const (
+	SkippedStage    ViolationKind = "skipped_stage"
+	BackwardMove    ViolationKind = "backward_move"
+	StageBounce     ViolationKind = "bounce"
+	WonWithoutOffer ViolationKind = "won_without_offer"
+)
+
+// StageTransition is a single move of a deal between two stages
+type StageTransition struct {
+	From string
+	To   string
+	At   Time
+}
+
+func (st StageTransition) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", st.From, st.To, st.At.String())
+}
+
+// ProcessViolation reports a deal and the transitions violating a rule
+type ProcessViolation struct {
+	DealID      int
+	Title       string
+	Kind        ViolationKind
+	Transitions []StageTransition
+	Detail      string
+}
+
+type ProcessViolations []ProcessViolation
+
+// Transitions returns the stage changes of the deal including the final move into StateWon or StateLost
+func (cr PipelineChangeResult) Transitions() []StageTransition {
+	ts := []StageTransition{}
+	updates := cr.sortedUpdates()
+	for i := 1; i < len(updates); i++ {
+		if updates[i].Phase == updates[i-1].Phase {
+			continue
+		}
+		ts = append(ts, StageTransition{From: updates[i-1].Phase, To: updates[i].Phase, At: updates[i].PiT})
+	}
+	if closed := cr.Deal.closedAt(); closed != nil && len(updates) > 0 {
+		to := StateLost
+		if cr.Deal.Status == "won" {
+			to = StateWon
+		}
+		ts = append(ts, StageTransition{From: updates[len(updates)-1].Phase, To: to, At: *closed})
+	}
+	return ts
+}
+
+// CheckProcess reports deals that skipped required stages, moved backwards,
+// bounced between two stages or were won without passing the offer stage.
+func CheckProcess(results PipelineChangeResults, stages Stages, rules ProcessRules) ProcessViolations {
+	order := map[string]int{
+		StateWon:  math.MaxInt32,
+		StateLost: math.MaxInt32,
+	}
+	pipeline := make(map[string]int)
+	for _, s := range stages {
+		order[s.Name] = s.OrderNr
+		pipeline[s.Name] = s.PipelineID
+	}
+
+	if rules.MaxBounces <= 0 {
+		rules.MaxBounces = 1
+	}
+
+	violations := ProcessViolations{}
+	for _, cr := range results {
+		report := func(kind ViolationKind, detail string, ts ...StageTransition) {
+			violations = append(violations, ProcessViolation{
+				DealID:      cr.Deal.ID,
+				Title:       cr.Deal.Title,
+				Kind:        kind,
+				Transitions: ts,
+				Detail:      detail,
+			})
+		}
+
+		phases := cr.Phases()
+		if len(phases) == 0 {
+			continue
+		}
+		required := rules.RequiredStages[pipeline[phases[0]]]
+		visited := map[string]bool{phases[0]: true}
+		bounces := make(map[string][]StageTransition)
+		lastBounce := make(map[string]int)
+
+		ts := cr.Transitions()
+		for i, t := range ts {
+			from, fok := order[t.From]
+			to, tok := order[t.To]
+			if fok && tok && t.To != StateLost {
+				if to < from && !rules.AllowBackward {
+					report(BackwardMove, fmt.Sprintf("moved back from %s to %s", t.From, t.To), t)
+				}
+				missing := []string{}
+				for _, r := range required {
+					o, e := order[r]
+					if e && o > from && o < to && !visited[r] {
+						missing = append(missing, r)
+					}
+				}
+				if len(missing) > 0 {
+					report(SkippedStage, "skipped "+strings.Join(missing, ", "), t)
+				}
+			}
+			visited[t.To] = true
+
+			if i > 0 && ts[i-1].From == t.To && ts[i-1].To == t.From {
+				pair := []string{t.From, t.To}
+				sort.Strings(pair)
+				key := strings.Join(pair, "\x00")
+				if last, e := lastBounce[key]; !e || last != i-1 {
+					bounces[key] = append(bounces[key], ts[i-1])
+				}
+				bounces[key] = append(bounces[key], t)
+				lastBounce[key] = i
+			}
+		}
+
+		keys := make([]string, 0, len(bounces))
+		for k := range bounces {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			bts := bounces[k]
+			if len(bts)/2 > rules.MaxBounces {
+				report(StageBounce, fmt.Sprintf("bounced %d times between %s", len(bts)/2, strings.Replace(k, "\x00", " and ", 1)), bts...)
+			}
+		}
+
+		if cr.Deal.Status == "won" && rules.OfferStage != "" && !visited[rules.OfferStage] {
+			if len(ts) > 0 {
+				report(WonWithoutOffer, "won without passing "+rules.OfferStage, ts[len(ts)-1])
+			} else {
+				report(WonWithoutOffer, "won without passing "+rules.OfferStage)
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,93 @@
+package pipedrive
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Keys of the deal fields carrying the marketing attribution
+const (
+	SourceFieldKey = "898dea9060ea3bb803e6a4f58c3c780b44e77cf7"
+	MediumFieldKey = "1ed188d19ec50c6563dbdad533126dc58882b429"
+)
+
+// AttributionRow holds the figures of one source/medium combination.
+// Cohort is the first day of the month the deals were added in, or zero for the overall figures.
+type AttributionRow struct {
+	Source     string    `json:"source"`
+	Medium     string    `json:"medium"`
+	Cohort     time.Time `json:"cohort"`
+	Created    int       `json:"created"`
+	Won        int       `json:"won"`
+	Revenue    float64   `json:"revenue"`
+	Conversion float64   `json:"conversion"`
+}
+
+// Attribution reports deals by lead source and medium, overall and per cohort month
+type Attribution struct {
+	Sources []AttributionRow `json:"sources"`
+	Cohorts []AttributionRow `json:"cohorts"`
+}
+
+// NewAttribution computes the attribution report of the given deals.
+// Option IDs are resolved to labels using the given deal fields.
+// The medium is read from the custom fields mapped by WithCustomDealFields.
+func NewAttribution(deals DealRefs, fields []DealField) Attribution {
+	byKey := make(map[string]DealField)
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	sources := make(map[string]*AttributionRow)
+	cohorts := make(map[string]*AttributionRow)
+	add := func(rows map[string]*AttributionRow, source, medium string, cohort time.Time, d DealRef) {
+		key := fmt.Sprintf("%s\x00%s\x00%d", source, medium, cohort.Unix())
+		r, e := rows[key]
+		if !e {
+			r = &AttributionRow{Source: source, Medium: medium, Cohort: cohort}
+			rows[key] = r
+		}
+		r.Created++
+		if d.Status == "won" {
+			r.Won++
+			r.Revenue += d.Value
+		}
+	}
+
+	for _, d := range deals {
+		source := byKey[SourceFieldKey].OptionLabel(d.Source)
+		medium := ""
+		if m, ok := d.CustomFields["Lead - Quelle / Medium"].(string); ok {
+			medium = byKey[MediumFieldKey].OptionLabel(m)
+		}
+		added := d.Added.Local()
+		cohort := time.Date(added.Year(), added.Month(), 1, 0, 0, 0, 0, time.Local)
+
+		add(sources, source, medium, time.Time{}, d)
+		add(cohorts, source, medium, cohort, d)
+	}
+
+	return Attribution{
+		Sources: sortedAttributionRows(sources),
+		Cohorts: sortedAttributionRows(cohorts),
+	}
+}
+
+func sortedAttributionRows(rows map[string]*AttributionRow) []AttributionRow {
+	res := make([]AttributionRow, 0, len(rows))
+	for _, r := range rows {
+		r.Conversion = float64(r.Won) / float64(r.Created)
+		res = append(res, *r)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if !res[i].Cohort.Equal(res[j].Cohort) {
+			return res[i].Cohort.Before(res[j].Cohort)
+		}
+		if res[i].Source != res[j].Source {
+			return res[i].Source < res[j].Source
+		}
+		return res[i].Medium < res[j].Medium
+	})
+	return res
+}
@@ -44,6 +44,9 @@ func main() {
 	var showVariables = false
 	flag.BoolVar(&showVariables, "showVariables", showVariables, "dump a sample deal")
 
+	var snapshot = ""
+	flag.StringVar(&snapshot, "snapshot", "", "read deals from the snapshot in this directory instead of the API")
+
 	flag.Parse()
 
 	apiOptions := []pipedrive.Option{
@@ -52,8 +55,12 @@ func main() {
 		pipedrive.WithCustomDealFields(),
 	}
 
-	switch token {
-	case "":
+	throttle := 1 * time.Second
+	switch {
+	case snapshot != "":
+		apiOptions[0] = pipedrive.FromSnapshot(snapshot)
+		throttle = 0
+	case token == "":
 		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
 	default:
 		apiOptions = append(apiOptions, pipedrive.FixedToken(token))
@@ -118,7 +125,7 @@ func main() {
 			copy(tmp, deals)
 			copydone()
 			//logrus.Infof("selectDeals with %d deals", len(deals))
-			err := selectDeals(pd, tmp, out)
+			err := selectDeals(pd, tmp, out, throttle)
 			if err != nil {
 				panic(err)
 			}
@@ -202,7 +209,7 @@ func printDeal(deal interface{}) error {
 	return tmpl.Execute(os.Stdout, deal)
 }
 
-func selectDeals(pd *pipedrive.API, dealIDs []string, out chan<- interface{}) error {
+func selectDeals(pd *pipedrive.API, dealIDs []string, out chan<- interface{}, throttle time.Duration) error {
 	for _, dealString := range dealIDs {
 		dealID, err := strconv.Atoi(dealString)
 		if err != nil {
@@ -218,7 +225,7 @@ func selectDeals(pd *pipedrive.API, dealIDs []string, out chan<- interface{}) er
 			return err
 		}
 		out <- deal
-		time.Sleep(time.Until(start.Add(1 * time.Second)))
+		time.Sleep(time.Until(start.Add(throttle)))
 	}
 	return nil
 }
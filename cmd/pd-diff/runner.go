@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func main() {
+	var before = ""
+	flag.StringVar(&before, "before", "", "directory of the earlier snapshot")
+
+	var after = ""
+	flag.StringVar(&after, "after", "", "directory of the later snapshot")
+
+	var format = "text"
+	flag.StringVar(&format, "format", format, "output format: text or json")
+
+	flag.Parse()
+
+	if before == "" || after == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	oldSnapshot, err := pipedrive.ReadSnapshot(before)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	newSnapshot, err := pipedrive.ReadSnapshot(after)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	diff, err := pipedrive.DiffSnapshots(oldSnapshot, newSnapshot)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(diff)
+	case "text":
+		err = writeText(os.Stdout, diff)
+	default:
+		err = fmt.Errorf("unknown format '%s'", format)
+	}
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+func writeText(w io.Writer, diff pipedrive.SnapshotDiff) error {
+	if diff.Empty() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	section := func(title string, n int) {
+		if n > 0 {
+			fmt.Fprintf(w, "%s (%d)\n", title, n)
+		}
+	}
+
+	section("Created", len(diff.Created))
+	for _, d := range diff.Created {
+		fmt.Fprintf(w, "  #%d %s, %.2f\n", d.ID, d.Title, d.Value)
+	}
+
+	section("Deleted", len(diff.Deleted))
+	for _, d := range diff.Deleted {
+		fmt.Fprintf(w, "  #%d %s, %.2f\n", d.ID, d.Title, d.Value)
+	}
+
+	section("Stage movements", len(diff.Moved))
+	for _, m := range diff.Moved {
+		direction := "back"
+		if m.Forward() {
+			direction = "forward"
+		}
+		fmt.Fprintf(w, "  #%d %s: %s -> %s (%s)\n", m.ID, m.Title, m.FromStage.Name, m.ToStage.Name, direction)
+	}
+
+	section("Changed", len(diff.Changed))
+	for _, c := range diff.Changed {
+		fmt.Fprintf(w, "  #%d %s\n", c.ID, c.Title)
+		for _, f := range c.Changes {
+			fmt.Fprintf(w, "    %s: %q -> %q\n", f.Field, f.Before, f.After)
+		}
+	}
+	return nil
+}
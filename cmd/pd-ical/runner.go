@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func main() {
+	var token = ""
+	flag.StringVar(&token, "token", "", "API token to be used (default $PDTOKEN)")
+
+	var verbose = false
+	flag.BoolVar(&verbose, "verbose", verbose, "enable verbose output")
+
+	var userID = 0
+	flag.IntVar(&userID, "user", 0, "export the activities of this user, 0 exports all users")
+
+	var domain = ""
+	flag.StringVar(&domain, "domain", "", "Pipedrive company domain used for deal links")
+
+	var days = 90
+	flag.IntVar(&days, "days", days, "number of past days to include")
+
+	var out = ""
+	flag.StringVar(&out, "out", "", "file to write the calendar to (default stdout)")
+
+	flag.Parse()
+
+	apiOptions := []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+	}
+
+	switch token {
+	case "":
+		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
+	default:
+		apiOptions = append(apiOptions, pipedrive.FixedToken(token))
+	}
+
+	if verbose {
+		apiOptions = append(apiOptions, pipedrive.LogURLs)
+	}
+
+	pd, err := pipedrive.NewAPI(apiOptions...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	w := os.Stdout
+	if out != "" {
+		w, err = os.Create(out)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	activities, errs := pd.FetchActivities(pipedrive.ActivityFilter{
+		UserID:    userID,
+		StartDate: time.Now().AddDate(0, 0, -days),
+	})
+	go func() {
+		for err := range errs {
+			logrus.Fatal(err)
+		}
+	}()
+
+	err = pipedrive.WriteICalendar(w, activities, pipedrive.ICalOptions{
+		CompanyDomain: domain,
+		Name:          "Pipedrive",
+	})
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	err = w.Close()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func main() {
+	var token = ""
+	flag.StringVar(&token, "token", "", "API token to be used (default $PDTOKEN)")
+
+	var verbose = false
+	flag.BoolVar(&verbose, "verbose", verbose, "enable verbose output")
+
+	var pipeline = ""
+	flag.StringVar(&pipeline, "pipeline", "", "name of the pipeline (mandatory)")
+
+	var filterID = 0
+	flag.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+
+	var maxDeals = 100
+	flag.IntVar(&maxDeals, "deals", maxDeals, "number of most recent deals shown as timeline")
+
+	var out = "report.html"
+	flag.StringVar(&out, "out", out, "file to write the report to")
+
+	flag.Parse()
+
+	if pipeline == "" {
+		logrus.Fatal("no pipeline given")
+	}
+
+	apiOptions := []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+	}
+
+	switch token {
+	case "":
+		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
+	default:
+		apiOptions = append(apiOptions, pipedrive.FixedToken(token))
+	}
+
+	if verbose {
+		apiOptions = append(apiOptions, pipedrive.LogURLs)
+	}
+
+	pd, err := pipedrive.NewAPI(apiOptions...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	r, err := buildReport(pd, pipeline, filterID, maxDeals)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	err = reportTemplate.Execute(f, r)
+	err2 := f.Close()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if err2 != nil {
+		logrus.Fatal(err2)
+	}
+}
+
+type funnelStep struct {
+	Stage   string
+	Deals   int
+	Share   float64
+	Percent template.CSS
+}
+
+type dwellRow struct {
+	Stage  string
+	Deals  int
+	Mean   float64
+	Median float64
+}
+
+type segment struct {
+	Phase string
+	Days  float64
+	Style template.CSS
+}
+
+type timeline struct {
+	ID       int
+	Title    string
+	Status   string
+	Segments []segment
+}
+
+type report struct {
+	Pipeline  string
+	Generated time.Time
+	From      time.Time
+	To        time.Time
+	Deals     int
+	Stages    []string
+	Colors    map[string]template.CSS
+	Funnel    []funnelStep
+	Dwell     []dwellRow
+	Timelines []timeline
+	Owners    pipedrive.OwnerLeaderboard
+}
+
+func buildReport(pd *pipedrive.API, pipeline string, filterID, maxDeals int) (report, error) {
+	r := report{
+		Pipeline:  pipeline,
+		Generated: time.Now(),
+		Colors:    make(map[string]template.CSS),
+	}
+
+	plID, err := pd.GetPipelineIDByName(pipeline)
+	if err != nil {
+		return r, err
+	}
+	stages, err := pd.RetrieveStagesForPipeline(plID)
+	if err != nil {
+		return r, err
+	}
+	if len(stages) == 0 {
+		return r, errors.New("pipeline has no stages")
+	}
+	deals, err := pd.FetchDealsFromPipeline(plID, filterID)
+	if err != nil {
+		return r, err
+	}
+	changes, err := pd.FetchPipelineChanges(deals, stages)
+	if err != nil {
+		return r, err
+	}
+	refs, err := pd.FetchDeals(filterID)
+	if err != nil {
+		return r, err
+	}
+
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].OrderNr < stages[j].OrderNr
+	})
+	for i, s := range stages {
+		r.Stages = append(r.Stages, s.Name)
+		r.Colors[s.Name] = template.CSS(fmt.Sprintf("background: hsl(%d, 55%%, 60%%)", 200+i*140/len(stages)))
+	}
+	r.Deals = len(changes)
+
+	r.Funnel = funnel(changes, r.Stages)
+	r.Dwell = dwell(changes, r.Stages)
+	r.Timelines, r.From, r.To = timelines(changes, r.Colors, maxDeals, r.Generated)
+
+	inPipeline := make(map[int]bool)
+	for _, s := range stages {
+		inPipeline[s.Id] = true
+	}
+	pipelineRefs := pipedrive.DealRefs{}
+	for _, d := range refs {
+		if inPipeline[d.Stage] {
+			pipelineRefs = append(pipelineRefs, d)
+		}
+	}
+	r.Owners = pipedrive.NewOwnerLeaderboard(pipelineRefs, time.Time{}, time.Time{})
+
+	return r, nil
+}
+
+// funnel counts the deals that reached each stage and the deals won
+func funnel(changes pipedrive.PipelineChangeResults, stages []string) []funnelStep {
+	reached := make(map[string]int)
+	for _, cr := range changes {
+		seen := make(map[string]bool)
+		for _, p := range cr.Phases() {
+			seen[p] = true
+		}
+		if cr.Deal.Status == "won" {
+			seen[pipedrive.StateWon] = true
+		}
+		for p := range seen {
+			reached[p]++
+		}
+	}
+
+	steps := []funnelStep{}
+	for _, s := range append(stages[:len(stages):len(stages)], pipedrive.StateWon) {
+		share := 0.0
+		if len(changes) > 0 {
+			share = float64(reached[s]) / float64(len(changes))
+		}
+		steps = append(steps, funnelStep{
+			Stage:   s,
+			Deals:   reached[s],
+			Share:   share,
+			Percent: template.CSS(fmt.Sprintf("width: %.1f%%", share*100)),
+		})
+	}
+	return steps
+}
+
+func dwell(changes pipedrive.PipelineChangeResults, stages []string) []dwellRow {
+	dt := pipedrive.NewDwellTimes(changes)
+	rows := []dwellRow{}
+	for _, s := range stages {
+		ds := dt[s]
+		row := dwellRow{Stage: s, Deals: len(ds)}
+		if len(ds) > 0 {
+			sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+			sum := time.Duration(0)
+			for _, d := range ds {
+				sum += d
+			}
+			row.Mean = sum.Hours() / 24 / float64(len(ds))
+			row.Median = ds[len(ds)/2].Hours() / 24
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// timelines lays out the stage segments of the most recent deals on a common time axis
+func timelines(changes pipedrive.PipelineChangeResults, colors map[string]template.CSS, max int, now time.Time) ([]timeline, time.Time, time.Time) {
+	recent := make(pipedrive.PipelineChangeResults, len(changes))
+	copy(recent, changes)
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].Deal.Added.After(recent[j].Deal.Added.Time)
+	})
+	if len(recent) > max {
+		recent = recent[:max]
+	}
+	if len(recent) == 0 {
+		return nil, now, now
+	}
+
+	from := recent[len(recent)-1].Deal.Added.Time
+	to := now
+	span := to.Sub(from).Seconds()
+	if span <= 0 {
+		span = 1
+	}
+
+	tls := []timeline{}
+	for _, cr := range recent {
+		tl := timeline{
+			ID:     cr.Deal.ID,
+			Title:  cr.Deal.Title,
+			Status: cr.Deal.Status,
+		}
+		end := now
+		switch {
+		case cr.Deal.Status == "won" && cr.Deal.WonAt != nil:
+			end = cr.Deal.WonAt.Time
+		case cr.Deal.Status == "lost" && cr.Deal.LostAt != nil:
+			end = cr.Deal.LostAt.Time
+		}
+
+		updates := make(pipedrive.DealFlowUpdates, len(cr.PipelineUpdates))
+		copy(updates, cr.PipelineUpdates)
+		sort.Stable(updates)
+		for i, u := range updates {
+			stop := end
+			if i+1 < len(updates) {
+				stop = updates[i+1].PiT.Time
+			}
+			start := u.PiT.Time
+			if start.Before(from) {
+				start = from
+			}
+			if !stop.After(start) {
+				continue
+			}
+			left := start.Sub(from).Seconds() / span * 100
+			width := stop.Sub(start).Seconds() / span * 100
+			tl.Segments = append(tl.Segments, segment{
+				Phase: u.Phase,
+				Days:  stop.Sub(start).Hours() / 24,
+				Style: template.CSS(fmt.Sprintf("left: %.2f%%; width: %.2f%%; %s", left, width, colors[u.Phase])),
+			})
+		}
+		tls = append(tls, tl)
+	}
+	return tls, from, to
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+	"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Pipeline {{.Pipeline}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0; }
+.meta { color: #777; margin-bottom: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 0.25em 0.75em; text-align: right; border-bottom: 1px solid #ddd; }
+th:first-child, td:first-child { text-align: left; }
+.bar { background: #eee; width: 30em; height: 1.2em; }
+.bar div { background: #4a90c2; height: 100%; }
+.legend span { display: inline-block; padding: 0.2em 0.6em; margin-right: 0.5em; border-radius: 3px; }
+.timeline td.track { width: 60em; }
+.track div { position: relative; height: 1.2em; background: #f4f4f4; }
+.track span { position: absolute; top: 0; height: 100%; }
+.won { color: #2a8a2a; }
+.lost { color: #b33; }
+</style>
+</head>
+<body>
+<h1>Pipeline {{.Pipeline}}</h1>
+<div class="meta">{{.Deals}} deals, generated {{date .Generated}}</div>
+
+<h2>Funnel</h2>
+<table>
+<tr><th>Stage</th><th>Deals</th><th>Share</th><th></th></tr>
+{{range .Funnel}}<tr><td>{{.Stage}}</td><td>{{.Deals}}</td><td>{{percent .Share}}</td><td><div class="bar"><div style="{{.Percent}}"></div></div></td></tr>
+{{end}}</table>
+
+<h2>Stage dwell times (days)</h2>
+<table>
+<tr><th>Stage</th><th>Deals</th><th>Mean</th><th>Median</th></tr>
+{{range .Dwell}}<tr><td>{{.Stage}}</td><td>{{.Deals}}</td><td>{{printf "%.1f" .Mean}}</td><td>{{printf "%.1f" .Median}}</td></tr>
+{{end}}</table>
+
+<h2>Owners</h2>
+<table>
+<tr><th>Owner</th><th>Won</th><th>Lost</th><th>Win rate</th><th>Avg value</th><th>Cycle days</th><th>Velocity</th></tr>
+{{range .Owners}}<tr><td>{{.Owner}}</td><td>{{.Won}}</td><td>{{.Lost}}</td><td>{{printf "%.2f" .WinRate}}</td><td>{{printf "%.2f" .AvgValue}}</td><td>{{printf "%.1f" .CycleDays}}</td><td>{{printf "%.2f" .Velocity}}</td></tr>
+{{end}}</table>
+
+<h2>Deal timelines</h2>
+<div class="legend">{{range .Stages}}<span style="{{index $.Colors .}}">{{.}}</span>{{end}}</div>
+<p class="meta">{{date .From}} – {{date .To}}</p>
+<table class="timeline">
+{{range .Timelines}}<tr><td>{{.ID}} {{.Title}}</td><td class="{{.Status}}">{{.Status}}</td><td class="track"><div>{{range .Segments}}<span style="{{.Style}}" title="{{.Phase}}: {{printf "%.1f" .Days}} days"></span>{{end}}</div></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
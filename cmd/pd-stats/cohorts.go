@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func cohorts(args []string) error {
+	fs := flag.NewFlagSet("cohorts", flag.ExitOnError)
+	o := commonFlags(fs)
+	var filterID = 0
+	fs.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+	var horizons = "30,60,90,180"
+	fs.StringVar(&horizons, "days", horizons, "comma separated days after creation to report")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	days := []int{}
+	for _, h := range strings.Split(horizons, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(h))
+		if err != nil {
+			return err
+		}
+		days = append(days, d)
+	}
+
+	pd, err := o.api()
+	if err != nil {
+		return err
+	}
+
+	deals, err := pd.FetchDeals(filterID)
+	if err != nil {
+		return err
+	}
+
+	cs := pipedrive.NewCohorts(deals, time.Now(), days...)
+
+	header := []string{"Cohort", "Deals"}
+	for _, d := range days {
+		header = append(header, fmt.Sprintf("Won %dd", d), fmt.Sprintf("Lost %dd", d), fmt.Sprintf("Open %dd", d))
+	}
+	rows := make([][]string, 0, len(cs))
+	for _, c := range cs {
+		row := []string{c.Month.Format("2006-01"), fmt.Sprintf("%d", c.Deals)}
+		for _, s := range c.Shares {
+			mark := ""
+			if !s.Complete {
+				mark = "*"
+			}
+			row = append(row,
+				fmt.Sprintf("%.2f%s", s.Won, mark),
+				fmt.Sprintf("%.2f%s", s.Lost, mark),
+				fmt.Sprintf("%.2f%s", s.Open, mark),
+			)
+		}
+		rows = append(rows, row)
+	}
+	return o.output(header, rows, cs)
+}
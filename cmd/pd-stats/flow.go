@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func flow(args []string) error {
+	fs := flag.NewFlagSet("flow", flag.ExitOnError)
+	o := commonFlags(fs)
+	var pipeline = ""
+	fs.StringVar(&pipeline, "pipeline", "", "name of the pipeline (mandatory)")
+	var filterID = 0
+	fs.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+	var dotFile = ""
+	fs.StringVar(&dotFile, "dot", "", "write a Graphviz DOT graph to this file")
+	var sankeyFile = ""
+	fs.StringVar(&sankeyFile, "sankey", "", "write Sankey JSON to this file")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if pipeline == "" {
+		return errors.New("no pipeline given")
+	}
+
+	pd, err := o.api()
+	if err != nil {
+		return err
+	}
+
+	changes, stages, err := fetchPipelineChanges(pd, pipeline, filterID)
+	if err != nil {
+		return err
+	}
+
+	f := pipedrive.NewFlow(changes, stages)
+
+	if dotFile != "" {
+		err = writeFile(dotFile, f.WriteDOT)
+		if err != nil {
+			return err
+		}
+	}
+	if sankeyFile != "" {
+		err = writeFile(sankeyFile, f.WriteSankey)
+		if err != nil {
+			return err
+		}
+	}
+
+	header := []string{"From", "To", "Deals", "Value"}
+	rows := make([][]string, 0, len(f.Links))
+	for _, l := range f.Links {
+		rows = append(rows, []string{l.Source, l.Target, fmt.Sprintf("%d", l.Count), fmt.Sprintf("%.2f", l.Value)})
+	}
+	return o.output(header, rows, f)
+}
+
+func fetchPipelineChanges(pd *pipedrive.API, pipeline string, filterID int) (pipedrive.PipelineChangeResults, pipedrive.Stages, error) {
+	plID, err := pd.GetPipelineIDByName(pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	stages, err := pd.RetrieveStagesForPipeline(plID)
+	if err != nil {
+		return nil, nil, err
+	}
+	deals, err := pd.FetchDealsFromPipeline(plID, filterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := pd.FetchPipelineChanges(deals, stages)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changes, stages, nil
+}
+
+func writeFile(name string, write func(w io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	err2 := f.Close()
+	if err != nil {
+		return err
+	}
+	return err2
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func followup(args []string) error {
+	fs := flag.NewFlagSet("followup", flag.ExitOnError)
+	o := commonFlags(fs)
+	var filterID = 0
+	fs.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+	var slaDays = 14
+	fs.IntVar(&slaDays, "sla", slaDays, "maximum days since the last activity of an open deal, 0 disables the check")
+	var stageSLA = ""
+	fs.StringVar(&stageSLA, "stage-sla", "", "SLA days per stage ID overriding -sla, e.g. 3=7,4=21")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	rules := pipedrive.FollowUpRules{
+		SLA:      time.Duration(slaDays) * 24 * time.Hour,
+		StageSLA: make(map[int]time.Duration),
+	}
+	if stageSLA != "" {
+		for _, kv := range strings.Split(stageSLA, ",") {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid stage SLA '%s'", kv)
+			}
+			stage, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+			if err != nil {
+				return err
+			}
+			days, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return err
+			}
+			rules.StageSLA[stage] = time.Duration(days) * 24 * time.Hour
+		}
+	}
+
+	pd, err := o.api()
+	if err != nil {
+		return err
+	}
+
+	deals, err := pd.FetchDeals(filterID)
+	if err != nil {
+		return err
+	}
+
+	undone := false
+	activities, errs := pd.FetchActivities(pipedrive.ActivityFilter{Done: &undone})
+	all := []pipedrive.Activity{}
+	for activities != nil || errs != nil {
+		select {
+		case a, ok := <-activities:
+			if !ok {
+				activities = nil
+				continue
+			}
+			all = append(all, a)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			return err
+		}
+	}
+
+	now := time.Now()
+	report := pipedrive.NewFollowUpReport(all, deals, rules, now)
+
+	header := []string{"User", "Problem", "ID", "Subject", "Since"}
+	rows := [][]string{}
+	for _, f := range report {
+		for _, a := range f.Overdue {
+			due, _ := a.Due()
+			rows = append(rows, []string{f.UserName, "overdue activity", strconv.Itoa(a.ID), a.Subject, due.Local().Format("2006-01-02 15:04")})
+		}
+		for _, d := range f.Unscheduled {
+			since := ""
+			if d.NextActivity != nil {
+				since = d.NextActivity.String()
+			}
+			rows = append(rows, []string{f.UserName, "no next activity", strconv.Itoa(d.ID), d.Title, since})
+		}
+		for _, s := range f.Stale {
+			rows = append(rows, []string{f.UserName, "SLA exceeded", strconv.Itoa(s.Deal.ID), s.Deal.Title, fmt.Sprintf("%.0f days", s.Age.Hours()/24)})
+		}
+	}
+	return o.output(header, rows, report)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func owners(args []string) error {
+	fs := flag.NewFlagSet("owners", flag.ExitOnError)
+	o := commonFlags(fs)
+	o.rangeFlags(fs)
+	var filterID = 0
+	fs.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := o.timeRange()
+	if err != nil {
+		return err
+	}
+
+	pd, err := o.api()
+	if err != nil {
+		return err
+	}
+
+	deals, err := pd.FetchDeals(filterID)
+	if err != nil {
+		return err
+	}
+
+	lb := pipedrive.NewOwnerLeaderboard(deals, from, to)
+
+	header := []string{"Owner", "Won", "Lost", "Win rate", "Avg value", "Cycle days", "Velocity"}
+	rows := make([][]string, 0, len(lb))
+	for _, s := range lb {
+		rows = append(rows, []string{
+			s.Owner,
+			fmt.Sprintf("%d", s.Won),
+			fmt.Sprintf("%d", s.Lost),
+			fmt.Sprintf("%.2f", s.WinRate),
+			fmt.Sprintf("%.2f", s.AvgValue),
+			fmt.Sprintf("%.1f", s.CycleDays),
+			fmt.Sprintf("%.2f", s.Velocity),
+		})
+	}
+	return o.output(header, rows, lb)
+}
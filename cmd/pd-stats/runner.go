@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+type command func(args []string) error
+
+var commands = map[string]command{
+	"cohorts":  cohorts,
+	"flow":     flow,
+	"followup": followup,
+	"owners":   owners,
+	"sources":  sources,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, e := commands[os.Args[1]]
+	if !e {
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd(os.Args[2:])
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: %s <%s> [flags]\n", os.Args[0], strings.Join(names, "|"))
+}
+
+// options holds the flags shared by all subcommands
+type options struct {
+	token   string
+	verbose bool
+	format  string
+	from    string
+	to      string
+}
+
+func commonFlags(fs *flag.FlagSet) *options {
+	o := &options{format: "table"}
+	fs.StringVar(&o.token, "token", "", "API token to be used (default $PDTOKEN)")
+	fs.BoolVar(&o.verbose, "verbose", false, "enable verbose output")
+	fs.StringVar(&o.format, "format", o.format, "output format: table, csv or json")
+	return o
+}
+
+// rangeFlags registers -from and -to for subcommands evaluating a time range
+func (o *options) rangeFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.from, "from", "", "start of the time range (YYYY-MM-DD)")
+	fs.StringVar(&o.to, "to", "", "end of the time range, exclusive (YYYY-MM-DD)")
+}
+
+func (o *options) api() (*pipedrive.API, error) {
+	apiOptions := []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+		pipedrive.WithCustomOrgFields(),
+		pipedrive.WithCustomDealFields(),
+	}
+
+	switch o.token {
+	case "":
+		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
+	default:
+		apiOptions = append(apiOptions, pipedrive.FixedToken(o.token))
+	}
+
+	if o.verbose {
+		apiOptions = append(apiOptions, pipedrive.LogURLs)
+	}
+
+	return pipedrive.NewAPI(apiOptions...)
+}
+
+func (o *options) timeRange() (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if o.from != "" {
+		from, err = time.ParseInLocation("2006-01-02", o.from, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+	}
+	if o.to != "" {
+		to, err = time.ParseInLocation("2006-01-02", o.to, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+	}
+	return from, to, nil
+}
+
+// output writes the rows in the selected format, json encodes v instead
+func (o *options) output(header []string, rows [][]string, v interface{}) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		err := w.Write(header)
+		if err != nil {
+			return err
+		}
+		err = w.WriteAll(rows)
+		if err != nil {
+			return err
+		}
+		return w.Error()
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown format '%s'", o.format)
+}
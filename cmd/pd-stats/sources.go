@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func sources(args []string) error {
+	fs := flag.NewFlagSet("sources", flag.ExitOnError)
+	o := commonFlags(fs)
+	var filterID = 0
+	fs.IntVar(&filterID, "filter", 0, "filter ID to use for all deals")
+	var byCohort = false
+	fs.BoolVar(&byCohort, "cohorts", byCohort, "break down by month the deals were added")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	pd, err := o.api()
+	if err != nil {
+		return err
+	}
+
+	deals, err := pd.FetchDeals(filterID)
+	if err != nil {
+		return err
+	}
+	fields, err := pd.FetchDealFields()
+	if err != nil {
+		return err
+	}
+
+	report := pipedrive.NewAttribution(deals, fields)
+
+	header := []string{"Source", "Medium", "Created", "Won", "Revenue", "Conversion"}
+	rows := report.Sources
+	if byCohort {
+		header = append([]string{"Cohort"}, header...)
+		rows = report.Cohorts
+	}
+	table := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		row := []string{
+			r.Source,
+			r.Medium,
+			fmt.Sprintf("%d", r.Created),
+			fmt.Sprintf("%d", r.Won),
+			fmt.Sprintf("%.2f", r.Revenue),
+			fmt.Sprintf("%.2f", r.Conversion),
+		}
+		if byCohort {
+			row = append([]string{r.Cohort.Format("2006-01")}, row...)
+		}
+		table = append(table, row)
+	}
+	return o.output(header, table, report)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func main() {
+	var token = ""
+	flag.StringVar(&token, "token", "", "API token to be used (default $PDTOKEN)")
+
+	var verbose = false
+	flag.BoolVar(&verbose, "verbose", verbose, "enable verbose output")
+
+	var dir = "snapshot"
+	flag.StringVar(&dir, "dir", dir, "directory of the snapshot")
+
+	var full = false
+	flag.BoolVar(&full, "full", full, "reload everything instead of applying the recent changes")
+
+	var watch = time.Duration(0)
+	flag.DurationVar(&watch, "watch", watch, "keep syncing in this interval instead of exiting")
+
+	flag.Parse()
+
+	apiOptions := []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+	}
+
+	switch token {
+	case "":
+		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
+	default:
+		apiOptions = append(apiOptions, pipedrive.FixedToken(token))
+	}
+
+	if verbose {
+		apiOptions = append(apiOptions, pipedrive.LogURLs)
+	}
+
+	pd, err := pipedrive.NewAPI(apiOptions...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	sync, err := pd.NewSync(dir)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	if full {
+		err = sync.FullLoad()
+	} else {
+		err = sync.Run()
+	}
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	logrus.Infof("synced into %s", filepath.Clean(dir))
+
+	for watch > 0 {
+		time.Sleep(watch)
+		err = sync.Incremental()
+		if err != nil {
+			logrus.Error(err)
+			continue
+		}
+		logrus.Debugf("synced into %s", filepath.Clean(dir))
+	}
+}
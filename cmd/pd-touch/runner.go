@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,7 +20,8 @@ import (
 )
 
 var (
-	verbose = false
+	verbose   = false
+	auditNote = true
 )
 
 func main() {
@@ -28,6 +30,8 @@ func main() {
 
 	flag.BoolVar(&verbose, "verbose", verbose, "enable verbose output")
 
+	flag.BoolVar(&auditNote, "note", auditNote, "leave an audit note on each modified deal")
+
 	flag.Parse()
 
 	apiOptions := []pipedrive.Option{
@@ -99,6 +103,16 @@ func main() {
 					return err
 				}
 			}
+
+			if auditNote {
+				_, err := pd.CreateNote(pipedrive.NoteParams{
+					DealID:  d.ID,
+					Content: pipedrive.HTMLFromText(fmt.Sprintf("pd-touch: Aktion changed from '%s' to '%s'", set2str(str2set(aktion)), set2str(newA))),
+				})
+				if err != nil {
+					return err
+				}
+			}
 		}
 		return nil
 	})
@@ -125,6 +139,15 @@ func str2set(val interface{}) map[string]struct{} {
 	return out
 }
 
+func set2str(set map[string]struct{}) string {
+	vals := make([]string, 0, len(set))
+	for k := range set {
+		vals = append(vals, k)
+	}
+	sort.Strings(vals)
+	return strings.Join(vals, ",")
+}
+
 func setAktionen(id string, aktionen map[string]struct{}) error {
 	type Payload struct {
 		Aktion string `json:"7de67a2875cf1fee9aa92dd0f8c65f5b24226b34"`
@@ -136,7 +159,7 @@ func setAktionen(id string, aktionen map[string]struct{}) error {
 	}
 
 	data := Payload{
-		Aktion: strings.Join(as, ","),
+		Aktion: set2str(aktionen),
 	}
 	payloadBytes, err := json.Marshal(data)
 	if err != nil {
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+func main() {
+	var token = ""
+	flag.StringVar(&token, "token", "", "API token to be used (default $PDTOKEN)")
+
+	var verbose = false
+	flag.BoolVar(&verbose, "verbose", verbose, "enable verbose output")
+
+	var config = ""
+	flag.StringVar(&config, "config", "", "JSON file with the desired webhook subscriptions (mandatory)")
+
+	var scope = ""
+	flag.StringVar(&scope, "scope", "", "only manage subscriptions whose URL starts with this prefix (default the URLs in the config)")
+
+	var apply = false
+	flag.BoolVar(&apply, "apply", apply, "apply the changes instead of only showing them")
+
+	flag.Parse()
+
+	if config == "" {
+		logrus.Fatal("no config given")
+	}
+
+	f, err := os.Open(config)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	var desired []pipedrive.WebhookSpec
+	err = json.NewDecoder(f).Decode(&desired)
+	f.Close()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	apiOptions := []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+	}
+
+	switch token {
+	case "":
+		apiOptions = append(apiOptions, pipedrive.EnvToken(""))
+	default:
+		apiOptions = append(apiOptions, pipedrive.FixedToken(token))
+	}
+
+	if verbose {
+		apiOptions = append(apiOptions, pipedrive.LogURLs)
+	}
+
+	pd, err := pipedrive.NewAPI(apiOptions...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	all, err := pd.FetchWebhooks()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	// without an explicit scope only subscriptions to exactly the configured URLs are touched,
+	// leaving those of other services alone
+	inScope := func(w pipedrive.Webhook) bool {
+		return strings.HasPrefix(w.SubscriptionURL, scope)
+	}
+	if scope == "" {
+		configured := make(map[string]bool)
+		for _, s := range desired {
+			configured[s.SubscriptionURL] = true
+		}
+		inScope = func(w pipedrive.Webhook) bool {
+			return configured[w.SubscriptionURL]
+		}
+	}
+	existing := []pipedrive.Webhook{}
+	for _, w := range all {
+		if inScope(w) {
+			existing = append(existing, w)
+		}
+	}
+
+	plan := pipedrive.PlanWebhooks(existing, desired)
+	fmt.Print(plan)
+
+	if plan.Empty() || !apply {
+		return
+	}
+	err = pd.ApplyWebhookPlan(plan)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package pipedrive
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultCohortHorizons are the days after creation reported by NewCohorts if none are given
+var DefaultCohortHorizons = []int{30, 60, 90, 180}
+
+// CohortShare holds the cumulative share of won, lost and open deals of a cohort
+// a given number of days after each deal was added.
+// Complete is false if some deals of the cohort have not reached that age yet.
+type CohortShare struct {
+	Days     int     `json:"days"`
+	Won      float64 `json:"won"`
+	Lost     float64 `json:"lost"`
+	Open     float64 `json:"open"`
+	Complete bool    `json:"complete"`
+}
+
+// Cohort groups the deals added in the same month
+type Cohort struct {
+	Month  time.Time     `json:"month"`
+	Deals  int           `json:"deals"`
+	Shares []CohortShare `json:"shares"`
+}
+
+type Cohorts []Cohort
+
+// NewCohorts buckets the deals by the month they were added and evaluates each bucket at the given horizons in days.
+// asOf is the point in time the deals were fetched at.
+func NewCohorts(deals DealRefs, asOf time.Time, horizons ...int) Cohorts {
+	if len(horizons) == 0 {
+		horizons = DefaultCohortHorizons
+	}
+
+	byMonth := make(map[time.Time]DealRefs)
+	for _, d := range deals {
+		added := d.Added.Local()
+		month := time.Date(added.Year(), added.Month(), 1, 0, 0, 0, 0, time.Local)
+		byMonth[month] = append(byMonth[month], d)
+	}
+
+	cohorts := make(Cohorts, 0, len(byMonth))
+	for month, ds := range byMonth {
+		c := Cohort{
+			Month: month,
+			Deals: len(ds),
+		}
+		for _, days := range horizons {
+			s := CohortShare{
+				Days:     days,
+				Complete: true,
+			}
+			won, lost := 0, 0
+			for _, d := range ds {
+				at := d.Added.Add(time.Duration(days) * 24 * time.Hour)
+				if at.After(asOf) {
+					s.Complete = false
+				}
+				switch {
+				case d.Status == "won" && d.WonAt != nil && !d.WonAt.After(at):
+					won++
+				case d.Status == "lost" && d.LostAt != nil && !d.LostAt.After(at):
+					lost++
+				}
+			}
+			s.Won = float64(won) / float64(len(ds))
+			s.Lost = float64(lost) / float64(len(ds))
+			s.Open = 1 - s.Won - s.Lost
+			c.Shares = append(c.Shares, s)
+		}
+		cohorts = append(cohorts, c)
+	}
+
+	sort.Slice(cohorts, func(i, j int) bool {
+		return cohorts[i].Month.Before(cohorts[j].Month)
+	})
+	return cohorts
+}
@@ -0,0 +1,272 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FieldChange is the before and after value of a changed deal field, resolved to labels
+type FieldChange struct {
+	Key    string `json:"key"`
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DealChange lists the changed fields of a deal present in both snapshots
+type DealChange struct {
+	ID      int           `json:"id"`
+	Title   string        `json:"title"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// StageMove is a deal that is in a different stage in the later snapshot
+type StageMove struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	FromStage Stage  `json:"from"`
+	ToStage   Stage  `json:"to"`
+}
+
+// Forward reports whether the deal moved to a later stage of the same pipeline
+func (m StageMove) Forward() bool {
+	return m.FromStage.PipelineID == m.ToStage.PipelineID && m.ToStage.OrderNr > m.FromStage.OrderNr
+}
+
+// SnapshotDiff lists the differences of the deals of two snapshots
+type SnapshotDiff struct {
+	Created DealRefs     `json:"created"`
+	Deleted DealRefs     `json:"deleted"`
+	Changed []DealChange `json:"changed"`
+	Moved   []StageMove  `json:"moved"`
+}
+
+// Empty reports whether the snapshots contain the same deals
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Created) == 0 && len(d.Deleted) == 0 && len(d.Changed) == 0 && len(d.Moved) == 0
+}
+
+// ignoredDiffKeys are deal attributes which are derived from other objects or change on every update
+var ignoredDiffKeys = map[string]bool{
+	"update_time":              true,
+	"stage_change_time":        true,
+	"stage_id":                 true,
+	"pipeline_id":              true,
+	"stage_order_nr":           true,
+	"rotten_time":              true,
+	"weighted_value":           true,
+	"weighted_value_currency":  true,
+	"formatted_value":          true,
+	"formatted_weighted_value": true,
+	"person_name":              true,
+	"org_name":                 true,
+	"owner_name":               true,
+	"cc_email":                 true,
+	"followers_count":          true,
+	"participants_count":       true,
+	"activities_count":         true,
+	"done_activities_count":    true,
+	"undone_activities_count":  true,
+	"email_messages_count":     true,
+	"notes_count":              true,
+	"files_count":              true,
+	"last_incoming_mail_time":  true,
+	"last_outgoing_mail_time":  true,
+	"last_activity_id":         true,
+	"next_activity_id":         true,
+	"next_activity_subject":    true,
+	"next_activity_type":       true,
+	"next_activity_duration":   true,
+	"next_activity_note":       true,
+	"next_activity_time":       true,
+}
+
+// DiffSnapshots compares the deals of two snapshots. Field names and option labels are
+// taken from the deal fields of the later snapshot, falling back to the earlier one.
+func DiffSnapshots(before, after *Snapshot) (SnapshotDiff, error) {
+	diff := SnapshotDiff{
+		Created: DealRefs{},
+		Deleted: DealRefs{},
+		Changed: []DealChange{},
+		Moved:   []StageMove{},
+	}
+
+	oldDeals, err := before.Records(SnapshotDeals)
+	if err != nil {
+		return diff, err
+	}
+	newDeals, err := after.Records(SnapshotDeals)
+	if err != nil {
+		return diff, err
+	}
+
+	fields := make(map[string]DealField)
+	stages := make(map[int]Stage)
+	for _, s := range []*Snapshot{before, after} {
+		dfs, err := s.DealFields()
+		if err != nil {
+			return diff, err
+		}
+		for _, f := range dfs {
+			fields[f.Key] = f
+		}
+		sts, err := s.Stages()
+		if err != nil {
+			return diff, err
+		}
+		for _, st := range sts {
+			stages[st.Id] = st
+		}
+	}
+
+	for _, id := range sortedRecordIDs(oldDeals, newDeals) {
+		oldData, inOld := oldDeals[id]
+		newData, inNew := newDeals[id]
+
+		switch {
+		case !inOld:
+			var d DealRef
+			err = json.Unmarshal(newData, &d)
+			if err != nil {
+				return diff, err
+			}
+			diff.Created = append(diff.Created, d)
+			continue
+		case !inNew:
+			var d DealRef
+			err = json.Unmarshal(oldData, &d)
+			if err != nil {
+				return diff, err
+			}
+			diff.Deleted = append(diff.Deleted, d)
+			continue
+		}
+
+		var oldDeal, newDeal map[string]json.RawMessage
+		err = json.Unmarshal(oldData, &oldDeal)
+		if err != nil {
+			return diff, err
+		}
+		err = json.Unmarshal(newData, &newDeal)
+		if err != nil {
+			return diff, err
+		}
+		title := renderValue(newDeal["title"], DealField{})
+
+		oldStage, _ := strconv.Atoi(string(oldDeal["stage_id"]))
+		newStage, _ := strconv.Atoi(string(newDeal["stage_id"]))
+		if oldStage != newStage {
+			from, e := stages[oldStage]
+			if !e {
+				from = Stage{Id: oldStage, Name: strconv.Itoa(oldStage)}
+			}
+			to, e := stages[newStage]
+			if !e {
+				to = Stage{Id: newStage, Name: strconv.Itoa(newStage)}
+			}
+			diff.Moved = append(diff.Moved, StageMove{ID: id, Title: title, FromStage: from, ToStage: to})
+		}
+
+		changes := diffFields(oldDeal, newDeal, fields)
+		if len(changes) > 0 {
+			diff.Changed = append(diff.Changed, DealChange{ID: id, Title: title, Changes: changes})
+		}
+	}
+
+	return diff, nil
+}
+
+func diffFields(before, after map[string]json.RawMessage, fields map[string]DealField) []FieldChange {
+	keys := make([]string, 0, len(after))
+	for k := range after {
+		keys = append(keys, k)
+	}
+	for k := range before {
+		if _, e := after[k]; !e {
+			keys = append(keys, k)
+		}
+	}
+
+	changes := []FieldChange{}
+	for _, k := range keys {
+		if ignoredDiffKeys[k] || reflect.DeepEqual(comparableValue(before[k]), comparableValue(after[k])) {
+			continue
+		}
+		field, e := fields[k]
+		name := field.Name
+		if !e {
+			name = k
+		}
+		changes = append(changes, FieldChange{
+			Key:    k,
+			Field:  name,
+			Before: renderValue(before[k], field),
+			After:  renderValue(after[k], field),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		fi, fj := fields[changes[i].Key], fields[changes[j].Key]
+		if fi.OrderNr != fj.OrderNr {
+			return fi.OrderNr < fj.OrderNr
+		}
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// comparableValue decodes a raw value, references to other objects are reduced to their id
+func comparableValue(data json.RawMessage) interface{} {
+	var v interface{}
+	if len(data) == 0 || json.Unmarshal(data, &v) != nil {
+		return nil
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		if id, e := obj["value"]; e {
+			return id
+		}
+		if id, e := obj["id"]; e {
+			return id
+		}
+	}
+	return v
+}
+
+// renderValue formats a raw value for humans, resolving option IDs and referenced objects
+func renderValue(data json.RawMessage, field DealField) string {
+	var v interface{}
+	if len(data) == 0 || json.Unmarshal(data, &v) != nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return field.OptionLabel(v)
+	case float64:
+		return field.OptionLabel(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		if name, ok := v["name"].(string); ok {
+			return name
+		}
+	}
+	return string(data)
+}
+
+func sortedRecordIDs(a, b Records) []int {
+	ids := make([]int, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, e := a[id]; !e {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,132 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FlowLink counts the deals that moved from one stage to another and sums up their value
+type FlowLink struct {
+	Source string
+	Target string
+	Count  int
+	Value  float64
+}
+
+// Flow describes the stage-to-stage movements of a set of deals
+type Flow struct {
+	Nodes []string
+	Links []FlowLink
+}
+
+// NewFlow aggregates the transitions of the given pipeline changes
+func NewFlow(results PipelineChangeResults, stages Stages) Flow {
+	m := NewTransitionMatrix(results, stages)
+	values := make(map[string]map[string]float64)
+	for _, cr := range results {
+		for _, t := range cr.Transitions() {
+			if values[t.From] == nil {
+				values[t.From] = make(map[string]float64)
+			}
+			values[t.From][t.To] += cr.Deal.Value
+		}
+	}
+
+	f := Flow{}
+	used := make(map[string]bool)
+	for _, from := range m.States {
+		for _, to := range m.States {
+			c := m.Counts[from][to]
+			if c == 0 {
+				continue
+			}
+			used[from], used[to] = true, true
+			f.Links = append(f.Links, FlowLink{
+				Source: from,
+				Target: to,
+				Count:  c,
+				Value:  values[from][to],
+			})
+		}
+	}
+	for _, s := range m.States {
+		if used[s] {
+			f.Nodes = append(f.Nodes, s)
+		}
+	}
+	return f
+}
+
+// WriteDOT renders the flow as a Graphviz digraph with the edges labeled by count and value
+func (f Flow) WriteDOT(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "digraph flow {")
+	fmt.Fprintln(b, "\trankdir=LR;")
+	fmt.Fprintln(b, "\tnode [shape=box];")
+	for _, n := range f.Nodes {
+		fmt.Fprintf(b, "\t%s;\n", dotQuote(n))
+	}
+	for _, l := range f.Links {
+		fmt.Fprintf(b, "\t%s -> %s [label=%s, penwidth=%d];\n",
+			dotQuote(l.Source), dotQuote(l.Target),
+			dotQuote(fmt.Sprintf("%d (%.0f)", l.Count, l.Value)),
+			1+l.Count/10)
+	}
+	fmt.Fprintln(b, "}")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteSankey writes the flow as JSON with nodes and links referencing the nodes by index.
+// Sankey layouts require an acyclic graph, so links going back to an earlier stage
+// are written to the separate series "backward" instead of "links".
+func (f Flow) WriteSankey(w io.Writer) error {
+	type node struct {
+		Name string `json:"name"`
+	}
+	type link struct {
+		Source int     `json:"source"`
+		Target int     `json:"target"`
+		Count  int     `json:"count"`
+		Value  float64 `json:"value"`
+	}
+	out := struct {
+		Nodes    []node `json:"nodes"`
+		Links    []link `json:"links"`
+		Backward []link `json:"backward"`
+	}{
+		Nodes:    make([]node, 0, len(f.Nodes)),
+		Links:    make([]link, 0, len(f.Links)),
+		Backward: []link{},
+	}
+
+	index := make(map[string]int)
+	for i, n := range f.Nodes {
+		index[n] = i
+		out.Nodes = append(out.Nodes, node{Name: n})
+	}
+	for _, l := range f.Links {
+		lnk := link{
+			Source: index[l.Source],
+			Target: index[l.Target],
+			Count:  l.Count,
+			Value:  l.Value,
+		}
+		// nodes are in pipeline order, won and lost come last
+		if lnk.Target <= lnk.Source {
+			out.Backward = append(out.Backward, lnk)
+			continue
+		}
+		out.Links = append(out.Links, lnk)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,46 @@
+package pipedrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSankeyWithoutCycles(t *testing.T) {
+	stages := Stages{
+		{Id: 1, Name: "Lead", OrderNr: 1},
+		{Id: 2, Name: "Angebot", OrderNr: 2},
+	}
+	results := PipelineChangeResults{
+		{Deal: Deal{ID: 1, Status: "won", Value: 10}, PipelineUpdates: phaseUpdates("Lead", "Angebot", "Lead", "Angebot")},
+	}
+
+	buf := &bytes.Buffer{}
+	err := NewFlow(results, stages).WriteSankey(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type link struct{ Source, Target, Count int }
+	var out struct {
+		Nodes    []struct{ Name string }
+		Links    []link
+		Backward []link
+	}
+	err = json.Unmarshal(buf.Bytes(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range out.Links {
+		if l.Target <= l.Source {
+			t.Errorf("backward link %s -> %s in links", out.Nodes[l.Source].Name, out.Nodes[l.Target].Name)
+		}
+	}
+	if len(out.Backward) != 1 || out.Nodes[out.Backward[0].Source].Name != "Angebot" || out.Nodes[out.Backward[0].Target].Name != "Lead" {
+		t.Errorf("expected Angebot -> Lead as backward link, got %+v", out.Backward)
+	}
+	if len(out.Links) != 2 {
+		t.Errorf("expected Lead -> Angebot and Angebot -> won, got %+v", out.Links)
+	}
+}
@@ -0,0 +1,131 @@
+package pipedrive
+
+import (
+	"sort"
+	"time"
+)
+
+// Due returns the point in time the activity is due.
+// Activities without a due time are due at the end of their due date.
+// Both are read in UTC, like all other dates and times of the API.
+func (a Activity) Due() (time.Time, bool) {
+	if a.DueDate == "" {
+		return time.Time{}, false
+	}
+	if a.DueTime != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04", a.DueDate+" "+a.DueTime, time.UTC)
+		if err == nil {
+			return t, true
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02", a.DueDate, time.UTC)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.AddDate(0, 0, 1), true
+}
+
+// FollowUpRules configures the SLA checks of NewFollowUpReport
+type FollowUpRules struct {
+	// SLA is the maximum time since the last activity of an open deal, zero disables the check
+	SLA time.Duration
+	// StageSLA overrides SLA by stage ID
+	StageSLA map[int]time.Duration
+}
+
+// StaleDeal is an open deal whose last activity is older than its SLA
+type StaleDeal struct {
+	Deal DealRef
+	Age  time.Duration
+	SLA  time.Duration
+}
+
+// FollowUps lists the follow-up problems of a single user
+type FollowUps struct {
+	UserID   int
+	UserName string
+	// Overdue holds undone activities past their due time
+	Overdue []Activity
+	// Unscheduled holds open deals without a future activity
+	Unscheduled DealRefs
+	// Stale holds open deals whose last activity is older than the SLA
+	Stale []StaleDeal
+}
+
+// NewFollowUpReport checks the given activities and deals and groups the findings by user.
+// Activities are grouped by AssignedToUserID, deals by their owner.
+func NewFollowUpReport(activities []Activity, deals DealRefs, rules FollowUpRules, now time.Time) []FollowUps {
+	byUser := make(map[int]*FollowUps)
+	user := func(id int, name string) *FollowUps {
+		f, e := byUser[id]
+		if !e {
+			f = &FollowUps{UserID: id}
+			byUser[id] = f
+		}
+		if f.UserName == "" {
+			f.UserName = name
+		}
+		return f
+	}
+
+	scheduled := make(map[int]bool)
+	for _, a := range activities {
+		if a.Done {
+			continue
+		}
+		due, ok := a.Due()
+		if !ok {
+			continue
+		}
+		if due.Before(now) {
+			id := a.AssignedToUserID
+			if id == 0 {
+				id = a.UserID
+			}
+			f := user(id, a.OwnerName)
+			f.Overdue = append(f.Overdue, a)
+			continue
+		}
+		if a.DealID != 0 {
+			scheduled[a.DealID] = true
+		}
+	}
+
+	// next activity dates are UTC dates like the due dates
+	utc := now.UTC()
+	today := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+	for _, d := range deals {
+		if d.Status != "open" {
+			continue
+		}
+		if !scheduled[d.ID] && (d.NextActivity == nil || d.NextActivity.Before(today)) {
+			f := user(d.User.ID, d.User.Name)
+			f.Unscheduled = append(f.Unscheduled, d)
+		}
+
+		sla, e := rules.StageSLA[d.Stage]
+		if !e {
+			sla = rules.SLA
+		}
+		if sla <= 0 {
+			continue
+		}
+		last := d.Added.Time
+		if d.LastActivity != nil {
+			last = d.LastActivity.Time
+		}
+		if age := now.Sub(last); age > sla {
+			f := user(d.User.ID, d.User.Name)
+			f.Stale = append(f.Stale, StaleDeal{Deal: d, Age: age, SLA: sla})
+		}
+	}
+
+	res := make([]FollowUps, 0, len(byUser))
+	for _, f := range byUser {
+		res = append(res, *f)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].UserID < res[j].UserID
+	})
+	return res
+}
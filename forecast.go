@@ -0,0 +1,215 @@
+package pipedrive
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DwellTimes holds the observed durations deals spent in each stage before moving on
+type DwellTimes map[string][]time.Duration
+
+// NewDwellTimes collects the dwell times from the given pipeline changes.
+// The current stage of open deals is not included as it has not been left yet.
+func NewDwellTimes(results PipelineChangeResults) DwellTimes {
+	dt := make(DwellTimes)
+	for _, cr := range results {
+		updates := cr.sortedUpdates()
+		for i, u := range updates {
+			var end time.Time
+			switch {
+			case i+1 < len(updates):
+				if updates[i+1].Phase == u.Phase {
+					continue
+				}
+				end = updates[i+1].PiT.Time
+			case cr.Deal.closedAt() != nil:
+				end = cr.Deal.closedAt().Time
+			default:
+				continue
+			}
+			start := u.PiT.Time
+			for j := i - 1; j >= 0 && updates[j].Phase == u.Phase; j-- {
+				start = updates[j].PiT.Time
+			}
+			dt[u.Phase] = append(dt[u.Phase], end.Sub(start))
+		}
+	}
+	return dt
+}
+
+// ForecastOptions controls the Monte Carlo revenue forecast
+type ForecastOptions struct {
+	// Runs is the number of simulated trajectories per deal, defaults to 1000
+	Runs int
+	// Months is the forecast horizon starting with the current month, defaults to 6
+	Months int
+	// Seed makes the forecast reproducible
+	Seed int64
+	// Now is the point in time of the snapshot, defaults to time.Now()
+	Now time.Time
+}
+
+// MonthForecast is the distribution of won revenue in a single month
+type MonthForecast struct {
+	Month time.Time
+	Mean  float64
+	P10   float64
+	P50   float64
+	P90   float64
+}
+
+// RevenueForecast holds the forecast for each month of the horizon
+type RevenueForecast struct {
+	Runs   int
+	Months []MonthForecast
+}
+
+// ForecastRevenue simulates the open deals through the historical stage transitions and dwell times.
+// The results are processed ordered by deal ID, so the same seed gives the same forecast
+// regardless of the order FetchPipelineChanges returned them in.
+func ForecastRevenue(results PipelineChangeResults, stages Stages, opts ForecastOptions) RevenueForecast {
+	if opts.Runs <= 0 {
+		opts.Runs = 1000
+	}
+	if opts.Months <= 0 {
+		opts.Months = 6
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	sorted := make(PipelineChangeResults, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Deal.ID < sorted[j].Deal.ID
+	})
+	results = sorted
+
+	m := NewTransitionMatrix(results, stages)
+	dwell := NewDwellTimes(results)
+	rnd := rand.New(rand.NewSource(opts.Seed))
+
+	start := time.Date(opts.Now.Year(), opts.Now.Month(), 1, 0, 0, 0, 0, opts.Now.Location())
+	end := start.AddDate(0, opts.Months, 0)
+
+	revenue := make([][]float64, opts.Months)
+	for i := range revenue {
+		revenue[i] = make([]float64, opts.Runs)
+	}
+
+	for _, cr := range results {
+		if cr.Deal.Status != "open" {
+			continue
+		}
+		updates := cr.sortedUpdates()
+		if len(updates) == 0 {
+			continue
+		}
+		last := updates[len(updates)-1]
+		for run := 0; run < opts.Runs; run++ {
+			wonAt, won := simulateDeal(rnd, m, dwell, last.Phase, last.PiT.Time, opts.Now, end)
+			if !won {
+				continue
+			}
+			month := (wonAt.Year()-start.Year())*12 + int(wonAt.Month()-start.Month())
+			if month < 0 || month >= opts.Months {
+				continue
+			}
+			revenue[month][run] += cr.Deal.Value
+		}
+	}
+
+	fc := RevenueForecast{Runs: opts.Runs}
+	for i, r := range revenue {
+		sort.Float64s(r)
+		sum := 0.0
+		for _, v := range r {
+			sum += v
+		}
+		fc.Months = append(fc.Months, MonthForecast{
+			Month: start.AddDate(0, i, 0),
+			Mean:  sum / float64(len(r)),
+			P10:   percentile(r, 0.1),
+			P50:   percentile(r, 0.5),
+			P90:   percentile(r, 0.9),
+		})
+	}
+	return fc
+}
+
+// simulateDeal walks a deal from its current stage until it is closed or leaves the horizon
+func simulateDeal(rnd *rand.Rand, m TransitionMatrix, dwell DwellTimes, state string, entered, now, end time.Time) (time.Time, bool) {
+	elapsed := now.Sub(entered)
+	t := entered
+	for step := 0; step < 100; step++ {
+		samples := dwell[state]
+		if len(samples) == 0 {
+			return time.Time{}, false
+		}
+
+		var d time.Duration
+		if step == 0 {
+			longer := []time.Duration{}
+			for _, s := range samples {
+				if s > elapsed {
+					longer = append(longer, s)
+				}
+			}
+			if len(longer) > 0 {
+				d = longer[rnd.Intn(len(longer))]
+			} else {
+				d = elapsed + samples[rnd.Intn(len(samples))]
+			}
+		} else {
+			d = samples[rnd.Intn(len(samples))]
+		}
+		t = t.Add(d)
+		if !t.Before(end) {
+			return time.Time{}, false
+		}
+
+		next, ok := nextState(rnd, m, state)
+		if !ok {
+			return time.Time{}, false
+		}
+		switch next {
+		case StateWon:
+			return t, true
+		case StateLost:
+			return time.Time{}, false
+		}
+		state = next
+	}
+	return time.Time{}, false
+}
+
+// nextState draws the successor of a state according to the observed transitions
+func nextState(rnd *rand.Rand, m TransitionMatrix, from string) (string, bool) {
+	total := m.Total(from)
+	if total == 0 {
+		return "", false
+	}
+	n := rnd.Intn(total)
+	for _, to := range m.States {
+		n -= m.Counts[from][to]
+		if n < 0 {
+			return to, true
+		}
+	}
+	return "", false
+}
+
+// percentile expects the values to be sorted
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
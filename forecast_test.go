@@ -0,0 +1,52 @@
+package pipedrive
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestForecastRevenueIgnoresInputOrder(t *testing.T) {
+	now := time.Date(2019, 6, 15, 0, 0, 0, 0, time.UTC)
+	at := func(days int) Time { return NewTime(now.AddDate(0, 0, days)) }
+	closed := func(days int) *Time { t := at(days); return &t }
+
+	stages := Stages{
+		{Id: 1, Name: "Lead", OrderNr: 1},
+		{Id: 2, Name: "Angebot", OrderNr: 2},
+	}
+	results := PipelineChangeResults{
+		{
+			Deal:            Deal{ID: 1, Status: "won", Value: 100, WonAt: closed(-80)},
+			PipelineUpdates: DealFlowUpdates{{Phase: "Lead", PiT: at(-120)}, {Phase: "Angebot", PiT: at(-100)}},
+		},
+		{
+			Deal:            Deal{ID: 2, Status: "lost", Value: 100, LostAt: closed(-50)},
+			PipelineUpdates: DealFlowUpdates{{Phase: "Lead", PiT: at(-90)}, {Phase: "Angebot", PiT: at(-60)}},
+		},
+		{
+			Deal:            Deal{ID: 3, Status: "won", Value: 100, WonAt: closed(-10)},
+			PipelineUpdates: DealFlowUpdates{{Phase: "Lead", PiT: at(-70)}, {Phase: "Angebot", PiT: at(-30)}},
+		},
+		{
+			Deal:            Deal{ID: 4, Status: "open", Value: 100},
+			PipelineUpdates: DealFlowUpdates{{Phase: "Lead", PiT: at(-5)}},
+		},
+		{
+			Deal:            Deal{ID: 5, Status: "open", Value: 200},
+			PipelineUpdates: DealFlowUpdates{{Phase: "Angebot", PiT: at(-3)}},
+		},
+	}
+
+	reversed := make(PipelineChangeResults, len(results))
+	for i, cr := range results {
+		reversed[len(results)-1-i] = cr
+	}
+
+	opts := ForecastOptions{Runs: 200, Months: 3, Seed: 42, Now: now}
+	a := ForecastRevenue(results, stages, opts)
+	b := ForecastRevenue(reversed, stages, opts)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("forecast depends on input order:\n%+v\n%+v", a, b)
+	}
+}
@@ -0,0 +1,149 @@
+package pipedrive
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ICalOptions controls the calendar written by WriteICalendar
+type ICalOptions struct {
+	// CompanyDomain is the Pipedrive subdomain used to link deals, e.g. "vitraum"
+	CompanyDomain string
+	// Name is shown as calendar name by most clients
+	Name string
+	// Now is used as DTSTAMP, defaults to time.Now()
+	Now time.Time
+}
+
+// WriteICalendar writes the activities as RFC 5545 calendar.
+// Activities with a due time become events, all-day activities become todos.
+func WriteICalendar(w io.Writer, activities <-chan Activity, opts ICalOptions) error {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	stamp := opts.Now.UTC().Format("20060102T150405Z")
+
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		writeFolded(bw, s)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//vitraum//golang-pipedrive//EN")
+	line("CALSCALE:GREGORIAN")
+	if opts.Name != "" {
+		line("X-WR-CALNAME:" + icalEscape(opts.Name))
+	}
+
+	for a := range activities {
+		due, ok := a.Due()
+		if !ok {
+			continue
+		}
+
+		component := "VTODO"
+		if a.DueTime != "" {
+			component = "VEVENT"
+		}
+		line("BEGIN:" + component)
+		line(fmt.Sprintf("UID:activity-%d@pipedrive.com", a.ID))
+		line("DTSTAMP:" + stamp)
+		line("SUMMARY:" + icalEscape(a.Subject))
+		if a.Type != "" {
+			line("CATEGORIES:" + icalEscape(a.Type))
+		}
+
+		if component == "VEVENT" {
+			line("DTSTART:" + due.UTC().Format("20060102T150405Z"))
+			if d, ok := activityDuration(a.Duration); ok {
+				line("DURATION:" + d)
+			}
+			if a.Done {
+				line("STATUS:CONFIRMED")
+			}
+		} else {
+			line("DUE;VALUE=DATE:" + strings.Replace(a.DueDate, "-", "", -1))
+			if a.Done {
+				line("STATUS:COMPLETED")
+			} else {
+				line("STATUS:NEEDS-ACTION")
+			}
+		}
+
+		description := a.NoteClean
+		if description == "" {
+			description = a.Note
+		}
+		if a.DealID != 0 && opts.CompanyDomain != "" {
+			link := fmt.Sprintf("https://%s.pipedrive.com/deal/%d", opts.CompanyDomain, a.DealID)
+			line("URL:" + link)
+			if description != "" {
+				description += "\n\n"
+			}
+			description += a.DealTitle + " " + link
+		}
+		if description != "" {
+			line("DESCRIPTION:" + icalEscape(description))
+		}
+
+		for _, p := range a.Participants {
+			cn := ""
+			if p.PrimaryFlag && a.PersonName != "" {
+				cn = ";CN=" + icalParam(a.PersonName)
+			}
+			line(fmt.Sprintf("ATTENDEE%s:urn:pipedrive:person:%d", cn, p.PersonID))
+		}
+		if a.OrgName != "" {
+			line("LOCATION:" + icalEscape(a.OrgName))
+		}
+		line("END:" + component)
+	}
+
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// activityDuration converts a duration like "01:30" into an iCalendar duration
+func activityDuration(d string) (string, bool) {
+	var h, m int
+	_, err := fmt.Sscanf(d, "%d:%d", &h, &m)
+	if err != nil || h*60+m == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("PT%dH%dM", h, m), true
+}
+
+func icalEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func icalParam(s string) string {
+	return `"` + strings.Replace(s, `"`, "'", -1) + `"`
+}
+
+// writeFolded writes a content line folded at 75 octets without splitting UTF-8 sequences
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,264 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// LeadValue is the potential value of a lead
+type LeadValue struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// Lead models an entry of the leads inbox
+type Lead struct {
+	ID                string     `json:"id"`
+	Title             string     `json:"title"`
+	OwnerID           int        `json:"owner_id"`
+	CreatorID         int        `json:"creator_id"`
+	LabelIDs          []string   `json:"label_ids"`
+	PersonID          int        `json:"person_id"`
+	OrganizationID    int        `json:"organization_id"`
+	SourceName        string     `json:"source_name"`
+	IsArchived        bool       `json:"is_archived"`
+	WasSeen           bool       `json:"was_seen"`
+	Value             *LeadValue `json:"value"`
+	ExpectedCloseDate *Date      `json:"expected_close_date"`
+	NextActivityID    int        `json:"next_activity_id"`
+	AddTime           time.Time  `json:"add_time"`
+	UpdateTime        time.Time  `json:"update_time"`
+	VisibleTo         string     `json:"visible_to"`
+	CCEmail           string     `json:"cc_email"`
+
+	// CustomFields holds the deal custom fields of the lead by their key
+	CustomFields CustomFieldMap `json:"-"`
+}
+
+// customFieldKey matches the generated keys of custom fields
+var customFieldKey = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// UnmarshalJSON collects the custom fields next to the regular fields
+func (l *Lead) UnmarshalJSON(buf []byte) error {
+	type lead Lead
+	err := json.Unmarshal(buf, (*lead)(l))
+	if err != nil {
+		return err
+	}
+	var jv map[string]interface{}
+	err = json.Unmarshal(buf, &jv)
+	if err != nil {
+		return err
+	}
+	l.CustomFields = customFields(jv)
+	return nil
+}
+
+// customFields picks the custom fields from a decoded object
+func customFields(jv map[string]interface{}) CustomFieldMap {
+	cf := make(CustomFieldMap)
+	for k, v := range jv {
+		if customFieldKey.MatchString(k) {
+			cf[k] = v
+		}
+	}
+	return cf
+}
+
+// LeadFilter restricts the leads returned by FetchLeads
+type LeadFilter struct {
+	// ArchivedStatus is one of "archived", "not_archived" or "all", defaults to "not_archived"
+	ArchivedStatus string
+	OwnerID        int
+	PersonID       int
+	OrganizationID int
+}
+
+func (f LeadFilter) values() url.Values {
+	v := url.Values{}
+	if f.ArchivedStatus != "" {
+		v.Set("archived_status", f.ArchivedStatus)
+	}
+	if f.OwnerID != 0 {
+		v.Set("owner_id", strconv.Itoa(f.OwnerID))
+	}
+	if f.PersonID != 0 {
+		v.Set("person_id", strconv.Itoa(f.PersonID))
+	}
+	if f.OrganizationID != 0 {
+		v.Set("organization_id", strconv.Itoa(f.OrganizationID))
+	}
+	return v
+}
+
+// FetchLeads returns all leads matching the filter
+func (pd *API) FetchLeads(filter LeadFilter) ([]Lead, error) {
+	leads := []Lead{}
+	err := pd.getAllJSON(pd.Endpoints.Leads, filter.values(), func(data json.RawMessage) error {
+		var page []Lead
+		err := json.Unmarshal(data, &page)
+		leads = append(leads, page...)
+		return err
+	})
+	return leads, err
+}
+
+// FetchLead returns the lead with the given id
+func (pd *API) FetchLead(id string) (Lead, error) {
+	var l Lead
+	err := pd.getJSON(fmt.Sprintf(pd.Endpoints.Lead, id), &l)
+	return l, err
+}
+
+// LeadParams holds the fields of a lead to be created or updated. Zero values are left out.
+type LeadParams struct {
+	Title             string     `json:"title,omitempty"`
+	OwnerID           int        `json:"owner_id,omitempty"`
+	LabelIDs          []string   `json:"label_ids,omitempty"`
+	PersonID          int        `json:"person_id,omitempty"`
+	OrganizationID    int        `json:"organization_id,omitempty"`
+	Value             *LeadValue `json:"value,omitempty"`
+	ExpectedCloseDate string     `json:"expected_close_date,omitempty"`
+	IsArchived        *bool      `json:"is_archived,omitempty"`
+	WasSeen           *bool      `json:"was_seen,omitempty"`
+
+	// CustomFields are sent by their key next to the regular fields
+	CustomFields CustomFieldMap `json:"-"`
+}
+
+// MarshalJSON merges the custom fields into the payload
+func (p LeadParams) MarshalJSON() ([]byte, error) {
+	type params LeadParams
+	buf, err := json.Marshal(params(p))
+	if err != nil || len(p.CustomFields) == 0 {
+		return buf, err
+	}
+	var jv map[string]interface{}
+	err = json.Unmarshal(buf, &jv)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range p.CustomFields {
+		jv[k] = v
+	}
+	return json.Marshal(jv)
+}
+
+// CreateLead adds a new lead and returns it
+func (pd *API) CreateLead(params LeadParams) (Lead, error) {
+	var l Lead
+	err := pd.sendJSON(pd.postEndpoint, pd.Endpoints.Leads, params, &l)
+	return l, err
+}
+
+// UpdateLead changes the given fields of a lead and returns the updated lead
+func (pd *API) UpdateLead(id string, params LeadParams) (Lead, error) {
+	var l Lead
+	err := pd.sendJSON(pd.patchEndpoint, fmt.Sprintf(pd.Endpoints.Lead, id), params, &l)
+	return l, err
+}
+
+// DeleteLead removes the lead with the given id
+func (pd *API) DeleteLead(id string) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.Lead, id), nil, nil)
+}
+
+// LeadLabel models a label that can be attached to leads
+type LeadLabel struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Color      string    `json:"color"`
+	AddTime    time.Time `json:"add_time"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+type leadLabelParams struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+// FetchLeadLabels returns all lead labels
+func (pd *API) FetchLeadLabels() ([]LeadLabel, error) {
+	var labels []LeadLabel
+	err := pd.getJSON(pd.Endpoints.LeadLabels, &labels)
+	return labels, err
+}
+
+// CreateLeadLabel adds a new label. Color is one of the colors offered by Pipedrive like "green" or "blue".
+func (pd *API) CreateLeadLabel(name, color string) (LeadLabel, error) {
+	var l LeadLabel
+	err := pd.sendJSON(pd.postEndpoint, pd.Endpoints.LeadLabels, leadLabelParams{Name: name, Color: color}, &l)
+	return l, err
+}
+
+// UpdateLeadLabel changes name and color of a label, empty values are left unchanged
+func (pd *API) UpdateLeadLabel(id, name, color string) (LeadLabel, error) {
+	var l LeadLabel
+	err := pd.sendJSON(pd.patchEndpoint, fmt.Sprintf(pd.Endpoints.LeadLabel, id), leadLabelParams{Name: name, Color: color}, &l)
+	return l, err
+}
+
+// DeleteLeadLabel removes the label with the given id
+func (pd *API) DeleteLeadLabel(id string) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.LeadLabel, id), nil, nil)
+}
+
+// ConvertLeadToDeal creates a deal from the lead in the given pipeline and stage and archives the lead.
+// Title, value, owner, person, organization, expected close date and custom fields are taken over.
+// A zero pipelineID or stageID selects the default.
+func (pd *API) ConvertLeadToDeal(leadID string, pipelineID, stageID int) (DealRef, error) {
+	lead, err := pd.FetchLead(leadID)
+	if err != nil {
+		return DealRef{}, err
+	}
+
+	payload := map[string]interface{}{
+		"title": lead.Title,
+	}
+	for k, v := range lead.CustomFields {
+		payload[k] = v
+	}
+	if lead.OwnerID != 0 {
+		payload["user_id"] = lead.OwnerID
+	}
+	if lead.PersonID != 0 {
+		payload["person_id"] = lead.PersonID
+	}
+	if lead.OrganizationID != 0 {
+		payload["org_id"] = lead.OrganizationID
+	}
+	if lead.Value != nil {
+		payload["value"] = lead.Value.Amount
+		payload["currency"] = lead.Value.Currency
+	}
+	if lead.ExpectedCloseDate != nil {
+		payload["expected_close_date"] = lead.ExpectedCloseDate.Format("2006-01-02")
+	}
+	if pipelineID != 0 {
+		payload["pipeline_id"] = pipelineID
+	}
+	if stageID != 0 {
+		payload["stage_id"] = stageID
+	}
+
+	var data json.RawMessage
+	err = pd.sendJSON(pd.postEndpoint, pd.Endpoints.AllDeals, payload, &data)
+	if err != nil {
+		return DealRef{}, err
+	}
+	deal, err := pd.DecodeDeal(data)
+	if err != nil {
+		return DealRef{}, err
+	}
+
+	archived := true
+	_, err = pd.UpdateLead(leadID, LeadParams{IsArchived: &archived})
+	if err != nil {
+		return deal, fmt.Errorf("deal %d created but lead not archived: %v", deal.ID, err)
+	}
+	return deal, nil
+}
@@ -0,0 +1,233 @@
+package pipedrive
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LostReason aggregates lost deals with similar lost reasons
+type LostReason struct {
+	// Reason is the most frequent spelling of the cluster
+	Reason   string
+	Variants []string
+	Count    int
+	Value    float64
+}
+
+type LostReasons []LostReason
+
+// LostReasonReport groups lost deals by reason, overall and by stage, owner and source.
+// Owners are keyed by user ID like in OwnerLeaderboard, OwnerNames holds their names.
+type LostReasonReport struct {
+	Reasons    LostReasons
+	ByStage    map[string]LostReasons
+	ByOwner    map[int]LostReasons
+	OwnerNames map[int]string
+	BySource   map[string]LostReasons
+}
+
+// lostReasonSimilarity is the minimum similarity of two normalized reasons to be clustered
+const lostReasonSimilarity = 0.8
+
+// NewLostReasonReport aggregates the deals lost within [from, to). A zero time leaves that end open.
+// The stage a deal was lost from is taken from its change history if present.
+// Sources are resolved to their labels using the given deal fields.
+func NewLostReasonReport(deals DealRefs, changes PipelineChangeResults, stages Stages, fields []DealField, from, to time.Time) LostReasonReport {
+	lostFrom := make(map[int]string)
+	for _, cr := range changes {
+		phases := cr.Phases()
+		if len(phases) > 0 {
+			lostFrom[cr.Deal.ID] = phases[len(phases)-1]
+		}
+	}
+	stageNames := make(map[int]string)
+	for _, s := range stages {
+		stageNames[s.Id] = s.Name
+	}
+	var sourceField DealField
+	for _, f := range fields {
+		if f.Key == SourceFieldKey {
+			sourceField = f
+		}
+	}
+
+	lost := DealRefs{}
+	for _, d := range deals {
+		if d.Status != "lost" || d.LostAt == nil {
+			continue
+		}
+		if !from.IsZero() && d.LostAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !d.LostAt.Before(to) {
+			continue
+		}
+		lost = append(lost, d)
+	}
+
+	clusters := clusterReasons(lost)
+
+	report := LostReasonReport{
+		ByStage:    make(map[string]LostReasons),
+		ByOwner:    make(map[int]LostReasons),
+		OwnerNames: make(map[int]string),
+		BySource:   make(map[string]LostReasons),
+	}
+	all := DealRefs{}
+	byStage := make(map[string]DealRefs)
+	byOwner := make(map[int]DealRefs)
+	bySource := make(map[string]DealRefs)
+	for _, d := range lost {
+		stage, e := lostFrom[d.ID]
+		if !e {
+			stage = stageNames[d.Stage]
+		}
+		all = append(all, d)
+		byStage[stage] = append(byStage[stage], d)
+		byOwner[d.User.ID] = append(byOwner[d.User.ID], d)
+		report.OwnerNames[d.User.ID] = d.User.Name
+		source := sourceField.OptionLabel(d.Source)
+		bySource[source] = append(bySource[source], d)
+	}
+
+	report.Reasons = clusters.aggregate(all)
+	for k, ds := range byStage {
+		report.ByStage[k] = clusters.aggregate(ds)
+	}
+	for k, ds := range byOwner {
+		report.ByOwner[k] = clusters.aggregate(ds)
+	}
+	for k, ds := range bySource {
+		report.BySource[k] = clusters.aggregate(ds)
+	}
+	return report
+}
+
+// reasonClusters maps each original lost reason to the spelling representing its cluster
+type reasonClusters struct {
+	representative map[string]string
+	variants       map[string][]string
+}
+
+func (rc reasonClusters) aggregate(deals DealRefs) LostReasons {
+	byReason := make(map[string]int)
+	res := LostReasons{}
+	for _, d := range deals {
+		r := rc.representative[d.LostReason]
+		i, e := byReason[r]
+		if !e {
+			res = append(res, LostReason{
+				Reason:   r,
+				Variants: rc.variants[r],
+			})
+			i = len(res) - 1
+			byReason[r] = i
+		}
+		res[i].Count++
+		res[i].Value += d.Value
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Reason < res[j].Reason
+	})
+	return res
+}
+
+func clusterReasons(deals DealRefs) reasonClusters {
+	counts := make(map[string]int)
+	for _, d := range deals {
+		counts[d.LostReason]++
+	}
+	reasons := make([]string, 0, len(counts))
+	for r := range counts {
+		reasons = append(reasons, r)
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	rc := reasonClusters{
+		representative: make(map[string]string),
+		variants:       make(map[string][]string),
+	}
+	type cluster struct {
+		normalized string
+		reason     string
+	}
+	clusters := []cluster{}
+	for _, r := range reasons {
+		n := normalizeReason(r)
+		match := -1
+		for i, c := range clusters {
+			if similarity(n, c.normalized) >= lostReasonSimilarity {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			clusters = append(clusters, cluster{normalized: n, reason: r})
+			match = len(clusters) - 1
+		}
+		rep := clusters[match].reason
+		rc.representative[r] = rep
+		rc.variants[rep] = append(rc.variants[rep], r)
+	}
+	return rc
+}
+
+// normalizeReason lowercases the reason and reduces punctuation and whitespace to single spaces
+func normalizeReason(r string) string {
+	fields := strings.FieldsFunc(strings.ToLower(r), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	return strings.Join(fields, " ")
+}
+
+// similarity returns 1 minus the normalized Levenshtein distance of a and b
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	max := len(ra)
+	if len(rb) > max {
+		max = len(rb)
+	}
+	if max == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(max)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(v int, vs ...int) int {
+	for _, x := range vs {
+		if x < v {
+			v = x
+		}
+	}
+	return v
+}
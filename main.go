@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -23,20 +25,48 @@ type endpoints struct {
 	DealField      string
 	DealActivities string
 	Organization   string
+	Activities     string
+	Activity       string
+	ActivityTypes  string
+	Notes          string
+	Note           string
+	Products       string
+	Product        string
+	ProductSearch  string
+	DealProducts   string
+	DealProduct    string
+	Users          string
+	User           string
+	CurrentUser    string
+	UserFind       string
+	AllDeals       string
+	Leads          string
+	Lead           string
+	LeadLabels     string
+	LeadLabel      string
+	Webhooks       string
+	Webhook        string
+	Recents        string
+	Persons        string
+	Organizations  string
 
 	DealFields         string
 	OrganizationFields string
+	PersonFields       string
 }
 
 type getEndpointFunc func(endpoint string) (*http.Response, error)
-type putEndpointFunc func(endpoint string, data io.Reader) (*http.Response, error)
+type sendEndpointFunc func(endpoint string, data io.Reader) (*http.Response, error)
 
 // API represents the information needed to access the Pipedrive API v1
 type API struct {
-	token       string
-	Endpoints   endpoints
-	getEndpoint getEndpointFunc
-	putEndpoint putEndpointFunc
+	token          string
+	Endpoints      endpoints
+	getEndpoint    getEndpointFunc
+	putEndpoint    sendEndpointFunc
+	postEndpoint   sendEndpointFunc
+	patchEndpoint  sendEndpointFunc
+	deleteEndpoint sendEndpointFunc
 
 	afterInit []Option
 
@@ -138,6 +168,147 @@ func (pd *API) PutGeneric(endpoint string, data io.Reader, results chan GenericR
 	return nil
 }
 
+// getJSON makes a GET request and decodes the data of the response into result
+func (pd *API) getJSON(endpoint string, result interface{}) error {
+	res, err := pd.getEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	return decodeResult(res, result)
+}
+
+// getAllJSON follows the pagination of a list endpoint and hands the data of each page to the given function
+func (pd *API) getAllJSON(endpoint string, values url.Values, page func(data json.RawMessage) error) error {
+	if values == nil {
+		values = url.Values{}
+	}
+	start := 0
+	for {
+		values.Set("start", strconv.Itoa(start))
+		res, err := pd.getEndpoint(endpoint + "?" + values.Encode())
+		if err != nil {
+			return err
+		}
+
+		var pres struct {
+			apiResult
+			Data json.RawMessage `json:"data"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&pres)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !pres.Success {
+			return fmt.Errorf("Pipedrive request failed: %s", pres.Error)
+		}
+		err = page(pres.Data)
+		if err != nil {
+			return err
+		}
+
+		if !pres.AdditionalData.Pagination.MoreItemsInCollection {
+			return nil
+		}
+
+		start += pres.AdditionalData.Pagination.Limit
+	}
+}
+
+// sendJSON encodes the payload as JSON, sends it using the given endpoint function
+// and decodes the data of the response into result unless it is nil
+func (pd *API) sendJSON(send sendEndpointFunc, endpoint string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		buf, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	res, err := send(endpoint, body)
+	if err != nil {
+		return err
+	}
+	return decodeResult(res, result)
+}
+
+func decodeResult(res *http.Response, result interface{}) error {
+	defer res.Body.Close()
+
+	var pres struct {
+		apiResult
+		Data json.RawMessage `json:"data"`
+	}
+	err := json.NewDecoder(res.Body).Decode(&pres)
+	if err != nil {
+		return err
+	}
+	if !pres.Success {
+		if pres.Error == "" {
+			return fmt.Errorf("Pipedrive request failed: %s", res.Status)
+		}
+		return fmt.Errorf("Pipedrive request failed: %s", pres.Error)
+	}
+	if result == nil || len(pres.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(pres.Data, result)
+}
+
+// DecodeDeal decodes a single deal object and maps its custom fields
+func (pd *API) DecodeDeal(data []byte) (DealRef, error) {
+	var dr DealRef
+	err := json.Unmarshal(data, &dr)
+	if err != nil {
+		return DealRef{}, err
+	}
+	if pd.mapFieldsDeal != nil {
+		var jv map[string]interface{}
+		err = json.Unmarshal(data, &jv)
+		if err != nil {
+			return DealRef{}, err
+		}
+		pd.mapFieldsDeal(&dr, jv)
+	}
+	return dr, nil
+}
+
+// DecodeOrganization decodes a single organization object and maps its custom fields
+func (pd *API) DecodeOrganization(data []byte) (Organization, error) {
+	var o Organization
+	err := json.Unmarshal(data, &o)
+	if err != nil {
+		return Organization{}, err
+	}
+	if pd.mapFieldsOrg != nil {
+		var jv map[string]interface{}
+		err = json.Unmarshal(data, &jv)
+		if err != nil {
+			return Organization{}, err
+		}
+		pd.mapFieldsOrg(&o, jv)
+	}
+	return o, nil
+}
+
+// DecodePerson decodes a single person object, custom fields are kept by their key
+func (pd *API) DecodePerson(data []byte) (Person, error) {
+	var p Person
+	err := json.Unmarshal(data, &p)
+	if err != nil {
+		return Person{}, err
+	}
+	var jv map[string]interface{}
+	err = json.Unmarshal(data, &jv)
+	if err != nil {
+		return Person{}, err
+	}
+	p.CustomFields = customFields(jv)
+	return p, nil
+}
+
 // FetchDeals returns a list of deals, optionally using a filter
 func (pd *API) FetchDeals(filterID int) (DealRefs, error) {
 	var deals DealRefs
@@ -158,19 +329,10 @@ func (pd *API) FetchDeals(filterID int) (DealRefs, error) {
 			return nil, err
 		}
 		for _, data := range pres.Data {
-			var dr DealRef
-			err = json.Unmarshal(data, &dr)
+			dr, err := pd.DecodeDeal(data)
 			if err != nil {
 				return nil, err
 			}
-			if pd.mapFieldsDeal != nil {
-				var jv map[string]interface{}
-				err = json.Unmarshal(data, &jv)
-				if err != nil {
-					return nil, err
-				}
-				pd.mapFieldsDeal(&dr, jv)
-			}
 			deals = append(deals, dr)
 		}
 
@@ -310,10 +472,10 @@ func (pd *API) FetchPipelineChanges(deals []Deal, stages Stages) (PipelineChange
 						continue
 					}
 					change := update.StoryData.ChangeLog[0]
-					if change.FieldName == "Phase" {
+					if phase, ok := change.NewValue.(string); ok && change.FieldName == "Phase" {
 						item := DealFlowUpdate{
 							PiT:   update.StoryData.AddTime,
-							Phase: change.NewValue.(string),
+							Phase: phase,
 						}
 						dealFlow.PipelineUpdates = append(dealFlow.PipelineUpdates, item)
 					}
@@ -333,7 +495,10 @@ func (pd *API) FetchPipelineChanges(deals []Deal, stages Stages) (PipelineChange
 		close(in)
 	}()
 
+	collected := sync.WaitGroup{}
+	collected.Add(2)
 	go func() {
+		defer collected.Done()
 		for df := range out {
 			res = append(res, df)
 		}
@@ -341,6 +506,7 @@ func (pd *API) FetchPipelineChanges(deals []Deal, stages Stages) (PipelineChange
 
 	errs := []error{}
 	go func() {
+		defer collected.Done()
 		for err := range errors {
 			errs = append(errs, err)
 		}
@@ -349,6 +515,7 @@ func (pd *API) FetchPipelineChanges(deals []Deal, stages Stages) (PipelineChange
 	wg.Wait()
 	close(out)
 	close(errors)
+	collected.Wait()
 
 	if len(errs) > 0 {
 		return nil, errs[0]
@@ -445,6 +612,18 @@ func (pd *API) GetDealFieldByID(id int) (DealField, error) {
 	return pres.Data, nil
 }
 
+// FetchDealFields returns all deal fields including their options
+func (pd *API) FetchDealFields() ([]DealField, error) {
+	fields := []DealField{}
+	err := pd.getAllJSON(pd.Endpoints.DealFields, nil, func(data json.RawMessage) error {
+		var page []DealField
+		err := json.Unmarshal(data, &page)
+		fields = append(fields, page...)
+		return err
+	})
+	return fields, err
+}
+
 func (pd *API) GenericStreamHelper(worker func(r GenericResponse) error, generator Urler, closer func()) <-chan error {
 	errs := make(chan error)
 	wg := sync.WaitGroup{}
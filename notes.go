@@ -0,0 +1,172 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Note models a note attached to a deal, person, organization or lead
+type Note struct {
+	ID                       int    `json:"id"`
+	UserID                   int    `json:"user_id"`
+	DealID                   int    `json:"deal_id"`
+	PersonID                 int    `json:"person_id"`
+	OrgID                    int    `json:"org_id"`
+	LeadID                   string `json:"lead_id"`
+	Content                  string `json:"content"`
+	AddTime                  *Time  `json:"add_time"`
+	UpdateTime               *Time  `json:"update_time"`
+	ActiveFlag               bool   `json:"active_flag"`
+	PinnedToDealFlag         bool   `json:"pinned_to_deal_flag"`
+	PinnedToPersonFlag       bool   `json:"pinned_to_person_flag"`
+	PinnedToOrganizationFlag bool   `json:"pinned_to_organization_flag"`
+	PinnedToLeadFlag         bool   `json:"pinned_to_lead_flag"`
+	LastUpdateUserID         int    `json:"last_update_user_id"`
+}
+
+var (
+	noteBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+	noteTags   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// PlainText returns the HTML content of the note as plain text
+func (n Note) PlainText() string {
+	s := noteBreaks.ReplaceAllString(n.Content, "\n")
+	s = noteTags.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// HTMLFromText converts plain text into note content, keeping line breaks
+func HTMLFromText(text string) string {
+	return strings.Replace(html.EscapeString(text), "\n", "<br>", -1)
+}
+
+// NoteFilter restricts the notes returned by FetchNotes. Zero values are ignored.
+type NoteFilter struct {
+	UserID   int
+	DealID   int
+	PersonID int
+	OrgID    int
+	LeadID   string
+
+	PinnedToDeal         *bool
+	PinnedToPerson       *bool
+	PinnedToOrganization *bool
+	PinnedToLead         *bool
+}
+
+func (f NoteFilter) values() url.Values {
+	v := url.Values{}
+	setInt := func(k string, i int) {
+		if i != 0 {
+			v.Set(k, strconv.Itoa(i))
+		}
+	}
+	setFlag := func(k string, b *bool) {
+		if b != nil {
+			v.Set(k, strconv.Itoa(*intFlag(b)))
+		}
+	}
+	setInt("user_id", f.UserID)
+	setInt("deal_id", f.DealID)
+	setInt("person_id", f.PersonID)
+	setInt("org_id", f.OrgID)
+	if f.LeadID != "" {
+		v.Set("lead_id", f.LeadID)
+	}
+	setFlag("pinned_to_deal_flag", f.PinnedToDeal)
+	setFlag("pinned_to_person_flag", f.PinnedToPerson)
+	setFlag("pinned_to_organization_flag", f.PinnedToOrganization)
+	setFlag("pinned_to_lead_flag", f.PinnedToLead)
+	return v
+}
+
+// FetchNotes streams all notes matching the filter
+func (pd *API) FetchNotes(filter NoteFilter) (<-chan Note, <-chan error) {
+	results := make(chan Note)
+
+	values := filter.values()
+	generator := func(offset int) (string, error) {
+		values.Set("start", strconv.Itoa(offset))
+		return pd.Endpoints.Notes + "?" + values.Encode(), nil
+	}
+	worker := func(r GenericResponse) error {
+		result := []Note{}
+		err := json.Unmarshal(r.Data, &result)
+		if err != nil {
+			return err
+		}
+		for _, n := range result {
+			results <- n
+		}
+		return nil
+	}
+	closer := func() { close(results) }
+	errs := pd.GenericStreamHelper(worker, generator, closer)
+
+	return results, errs
+}
+
+// FetchNote returns the note with the given id
+func (pd *API) FetchNote(id int) (Note, error) {
+	var n Note
+	err := pd.getJSON(fmt.Sprintf(pd.Endpoints.Note, id), &n)
+	return n, err
+}
+
+// NoteParams holds the fields of a note to be created or updated.
+// Content is HTML, use HTMLFromText for plain text. Zero values are left out.
+type NoteParams struct {
+	Content  string `json:"content,omitempty"`
+	DealID   int    `json:"deal_id,omitempty"`
+	PersonID int    `json:"person_id,omitempty"`
+	OrgID    int    `json:"org_id,omitempty"`
+	LeadID   string `json:"lead_id,omitempty"`
+
+	PinnedToDeal         *bool `json:"-"`
+	PinnedToPerson       *bool `json:"-"`
+	PinnedToOrganization *bool `json:"-"`
+	PinnedToLead         *bool `json:"-"`
+}
+
+// MarshalJSON encodes the pinned flags as 0 or 1 as expected by the API
+func (p NoteParams) MarshalJSON() ([]byte, error) {
+	type params NoteParams
+	return json.Marshal(struct {
+		params
+		PinnedToDeal         *int `json:"pinned_to_deal_flag,omitempty"`
+		PinnedToPerson       *int `json:"pinned_to_person_flag,omitempty"`
+		PinnedToOrganization *int `json:"pinned_to_organization_flag,omitempty"`
+		PinnedToLead         *int `json:"pinned_to_lead_flag,omitempty"`
+	}{
+		params:               params(p),
+		PinnedToDeal:         intFlag(p.PinnedToDeal),
+		PinnedToPerson:       intFlag(p.PinnedToPerson),
+		PinnedToOrganization: intFlag(p.PinnedToOrganization),
+		PinnedToLead:         intFlag(p.PinnedToLead),
+	})
+}
+
+// CreateNote adds a new note and returns it
+func (pd *API) CreateNote(params NoteParams) (Note, error) {
+	var n Note
+	err := pd.sendJSON(pd.postEndpoint, pd.Endpoints.Notes, params, &n)
+	return n, err
+}
+
+// UpdateNote changes the given fields of a note and returns the updated note
+func (pd *API) UpdateNote(id int, params NoteParams) (Note, error) {
+	var n Note
+	err := pd.sendJSON(pd.putEndpoint, fmt.Sprintf(pd.Endpoints.Note, id), params, &n)
+	return n, err
+}
+
+// DeleteNote removes the note with the given id
+func (pd *API) DeleteNote(id int) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.Note, id), nil, nil)
+}
@@ -1,6 +1,11 @@
 package pipedrive
 
-import "time"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Pipeline models the pipeline API object
 type Pipeline struct {
@@ -15,7 +20,8 @@ type Pipeline struct {
 }
 
 type apiResult struct {
-	Success        bool `json:"success"`
+	Success        bool   `json:"success"`
+	Error          string `json:"error"`
 	AdditionalData struct {
 		Pagination struct {
 			Start                 int  `json:"start"`
@@ -29,45 +35,103 @@ type apiResult struct {
 type DealRef struct {
 	ID int `json:"id"`
 	//"user_id": 872124,
-	Person struct {
-		ID    int    `json:"value"`
-		Name  string `json:"name"`
-		Email []struct {
-			Label   string `json:"label"`
-			Value   string `json:"value"`
-			Primary bool   `json:"primary"`
-		} `json:"email"`
-	} `json:"person_id"`
-	Organization struct {
-		ID   int    `json:"value"`
-		Name string `json:"name"`
-	} `json:"org_id"`
-	Stage           int     `json:"stage_id"`
-	StageChangetime *Time   `json:"stage_change_time"`
-	Title           string  `json:"title"`
-	Value           float64 `json:"value"`
-	Added           Time    `json:"add_time"`
-	Updated         *Time   `json:"update_time"`
-	Status          string  `json:"status"`
-	WonAt           *Time   `json:"won_time"`
-	LostAt          *Time   `json:"lost_time"`
-	LostReason      string  `json:"lost_reason"`
-	LastActivity    *Date   `json:"last_activity_date"`
-	Source          string  `json:"898dea9060ea3bb803e6a4f58c3c780b44e77cf7"`
-	LeadDate        string  `json:"19cef73a8ff77b70bf05736552155a7b9f97a36f"`
-	User            struct {
-		ID         int    `json:"id"`
-		Name       string `json:"name"`
-		Email      string `json:"email"`
-		HasPicture int    `json:"has_pic"`
-		Active     bool   `json:"active_flag"`
-		Value      int    `json:"value"`
-		// PicHash
-	} `json:"user_id"`
+	Person          PersonRef       `json:"person_id"`
+	Organization    OrganizationRef `json:"org_id"`
+	Stage           int             `json:"stage_id"`
+	StageChangetime *Time           `json:"stage_change_time"`
+	Title           string          `json:"title"`
+	Value           float64         `json:"value"`
+	Added           Time            `json:"add_time"`
+	Updated         *Time           `json:"update_time"`
+	Status          string          `json:"status"`
+	WonAt           *Time           `json:"won_time"`
+	LostAt          *Time           `json:"lost_time"`
+	LostReason      string          `json:"lost_reason"`
+	LastActivity    *Date           `json:"last_activity_date"`
+	NextActivity    *Date           `json:"next_activity_date"`
+	Source          string          `json:"898dea9060ea3bb803e6a4f58c3c780b44e77cf7"`
+	LeadDate        string          `json:"19cef73a8ff77b70bf05736552155a7b9f97a36f"`
+	ProductsCount   int             `json:"products_count"`
+	User            User            `json:"user_id"`
 
 	CustomFields CustomFieldMap
 }
 
+// ContactDetail is an email address or phone number of a person
+type ContactDetail struct {
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Primary bool   `json:"primary"`
+}
+
+// PersonRef is a person embedded in another object
+type PersonRef struct {
+	ID    int             `json:"value"`
+	Name  string          `json:"name"`
+	Email []ContactDetail `json:"email"`
+	Phone []ContactDetail `json:"phone"`
+}
+
+// UnmarshalJSON accepts a plain person id as well, as sent by webhooks
+func (p *PersonRef) UnmarshalJSON(buf []byte) error {
+	var id int
+	if json.Unmarshal(buf, &id) == nil {
+		*p = PersonRef{ID: id}
+		return nil
+	}
+	type ref PersonRef
+	return json.Unmarshal(buf, (*ref)(p))
+}
+
+// OrganizationRef is an organization embedded in another object
+type OrganizationRef struct {
+	ID   int    `json:"value"`
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON accepts a plain organization id as well, as sent by webhooks
+func (o *OrganizationRef) UnmarshalJSON(buf []byte) error {
+	var id int
+	if json.Unmarshal(buf, &id) == nil {
+		*o = OrganizationRef{ID: id}
+		return nil
+	}
+	type ref OrganizationRef
+	return json.Unmarshal(buf, (*ref)(o))
+}
+
+// Person models a Pipedrive person
+type Person struct {
+	ID                int             `json:"id"`
+	CompanyID         int             `json:"company_id"`
+	Owner             User            `json:"owner_id"`
+	Organization      OrganizationRef `json:"org_id"`
+	Name              string          `json:"name"`
+	FirstName         string          `json:"first_name"`
+	LastName          string          `json:"last_name"`
+	Phone             []ContactDetail `json:"phone"`
+	Email             []ContactDetail `json:"email"`
+	ActiveFlag        bool            `json:"active_flag"`
+	OpenDealsCount    int             `json:"open_deals_count"`
+	ClosedDealsCount  int             `json:"closed_deals_count"`
+	WonDealsCount     int             `json:"won_deals_count"`
+	LostDealsCount    int             `json:"lost_deals_count"`
+	ActivitiesCount   int             `json:"activities_count"`
+	LastActivityDate  *Date           `json:"last_activity_date"`
+	NextActivityDate  *Date           `json:"next_activity_date"`
+	AddTime           *Time           `json:"add_time"`
+	UpdateTime        *Time           `json:"update_time"`
+	VisibleTo         string          `json:"visible_to"`
+	CcEmail           string          `json:"cc_email"`
+	OwnerName         string          `json:"owner_name"`
+	OrgName           string          `json:"org_name"`
+	FollowersCount    int             `json:"followers_count"`
+	NotesCount        int             `json:"notes_count"`
+	EmailMessageCount int             `json:"email_messages_count"`
+
+	CustomFields CustomFieldMap `json:"-"`
+}
+
 type Activity struct {
 	ID                 int         `json:"id"`
 	CompanyID          int         `json:"company_id"`
@@ -128,6 +192,10 @@ type Deal struct {
 	LostReason      *string `json:"lost_reason"`
 	Source          string  `json:"898dea9060ea3bb803e6a4f58c3c780b44e77cf7"`
 	LeadDate        string  `json:"19cef73a8ff77b70bf05736552155a7b9f97a36f"`
+	ProductsCount   int     `json:"products_count"`
+
+	// Products holds the line items of the deal once loaded by LoadDealProducts
+	Products DealProducts `json:"-"`
 
 	CustomFields CustomFieldMap
 	/*
@@ -141,7 +209,6 @@ type Deal struct {
 	   "visible_to": "3",
 	   "close_time": null,
 	   "pipeline_id": 1,
-	   "products_count": null,
 	   "files_count": 3,
 	   "notes_count": 5,
 	   "followers_count": 1,
@@ -310,6 +377,7 @@ type DealField struct {
 	Name       string `json:"name"`
 	Key        string `json:"key"`
 	OrderNr    int    `json:"order_nr"`
+	FieldType  string `json:"field_type"`
 	AddTime    Time   `json:"add_time"`
 	UpdateTime Time   `json:"update_time"`
 	Options    *[]struct {
@@ -318,7 +386,6 @@ type DealField struct {
 	} `json:"options"`
 
 	/*
-	   "field_type": "enum",
 	   "active_flag": true,
 	   "edit_flag": true,
 	   "index_visible_flag": true,
@@ -329,19 +396,32 @@ type DealField struct {
 	*/
 }
 
+// OptionLabel resolves the option ID(s) stored in an enum or set field to their labels.
+// Values without a matching option are returned unchanged.
+func (df DealField) OptionLabel(value string) string {
+	if df.Options == nil || value == "" {
+		return value
+	}
+	ids := strings.Split(value, ",")
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		label := id
+		for _, o := range *df.Options {
+			if strconv.Itoa(o.ID) == strings.TrimSpace(id) {
+				label = o.Label
+				break
+			}
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, ", ")
+}
+
 type Organization struct {
-	ID        int    `json:"id"`
-	CompanyID int    `json:"company_id"`
-	Name      string `json:"name"`
-	OwnerID   struct {
-		ID         int         `json:"id"`
-		Name       string      `json:"name"`
-		Email      string      `json:"email"`
-		HasPic     int         `json:"has_pic"`
-		PicHash    interface{} `json:"pic_hash"`
-		ActiveFlag bool        `json:"active_flag"`
-		Value      int         `json:"value"`
-	} `json:"owner_id"`
+	ID                       int         `json:"id"`
+	CompanyID                int         `json:"company_id"`
+	Name                     string      `json:"name"`
+	OwnerID                  User        `json:"owner_id"`
 	OpenDealsCount           int         `json:"open_deals_count"`
 	RelatedOpenDealsCount    int         `json:"related_open_deals_count"`
 	ClosedDealsCount         int         `json:"closed_deals_count"`
@@ -0,0 +1,380 @@
+package pipedrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrOffline is returned for requests a snapshot cannot answer, e.g. writes or filters
+var ErrOffline = errors.New("not available offline")
+
+// FromSnapshot serves all reads from the snapshot in the given directory instead of the network.
+// Deals with their updates, persons, organizations, activities, pipelines, stages and fields
+// are available, so FetchPipelineChanges and the reports built on it work offline.
+// Writes and requests depending on server side state like filters fail with ErrOffline.
+// It fails if the directory does not contain a snapshot.
+func FromSnapshot(dir string) Option {
+	return func(a *API) error {
+		snapshot, err := ReadSnapshot(dir)
+		if err != nil {
+			return err
+		}
+		srv := &snapshotServer{
+			snapshot: snapshot,
+			records:  make(map[string]Records),
+		}
+
+		a.Endpoints = defaultEndpoints
+		a.getEndpoint = func(endpoint string) (*http.Response, error) {
+			a.logURL(endpoint)
+			return srv.get(endpoint)
+		}
+		offline := func(endpoint string, data io.Reader) (*http.Response, error) {
+			return nil, fmt.Errorf("%s: %w", endpoint, ErrOffline)
+		}
+		a.putEndpoint = offline
+		a.postEndpoint = offline
+		a.patchEndpoint = offline
+		a.deleteEndpoint = offline
+		return nil
+	}
+}
+
+// snapshotServer answers GET requests of the v1 API from a snapshot
+type snapshotServer struct {
+	snapshot *Snapshot
+
+	mu      sync.Mutex
+	records map[string]Records
+}
+
+// entity returns the records of an entity, the snapshot is read only once
+func (s *snapshotServer) entity(entity string) (Records, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if recs, e := s.records[entity]; e {
+		return recs, nil
+	}
+	recs, err := s.snapshot.Records(entity)
+	if err != nil {
+		return nil, err
+	}
+	s.records[entity] = recs
+	return recs, nil
+}
+
+func (s *snapshotServer) get(endpoint string) (*http.Response, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+
+	path := u.Path
+	if i := strings.Index(path, "/v1/"); i >= 0 {
+		path = path[i+len("/v1/"):]
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if q.Get("filter_id") != "" && q.Get("filter_id") != "0" {
+		return nil, fmt.Errorf("%s: filters %w", endpoint, ErrOffline)
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "deals":
+		return s.list(SnapshotDeals, q, nil)
+	case len(parts) == 2 && parts[0] == "deals":
+		return s.item(SnapshotDeals, parts[1])
+	case len(parts) == 3 && parts[0] == "deals" && parts[2] == "updates":
+		return s.dealUpdates(endpoint, parts[1], q)
+	case len(parts) == 3 && parts[0] == "deals" && parts[2] == "activities":
+		return s.list(SnapshotActivities, q, matchField("deal_id", parts[1]))
+	case len(parts) == 1 && parts[0] == "persons":
+		return s.list(SnapshotPersons, q, nil)
+	case len(parts) == 2 && parts[0] == "persons":
+		return s.item(SnapshotPersons, parts[1])
+	case len(parts) == 1 && parts[0] == "organizations":
+		return s.list(SnapshotOrganizations, q, nil)
+	case len(parts) == 2 && parts[0] == "organizations":
+		return s.item(SnapshotOrganizations, parts[1])
+	case len(parts) == 1 && parts[0] == "activities":
+		return s.list(SnapshotActivities, q, activityFilter(q))
+	case len(parts) == 2 && parts[0] == "activities":
+		return s.item(SnapshotActivities, parts[1])
+	case len(parts) == 1 && parts[0] == "pipelines":
+		return s.list(SnapshotPipelines, q, nil)
+	case len(parts) == 2 && parts[0] == "pipelines":
+		return s.item(SnapshotPipelines, parts[1])
+	case len(parts) == 3 && parts[0] == "pipelines" && parts[2] == "deals":
+		return s.pipelineDeals(q, parts[1])
+	case len(parts) == 1 && parts[0] == "stages":
+		if id := q.Get("pipeline_id"); id != "" {
+			return s.list(SnapshotStages, q, matchField("pipeline_id", id))
+		}
+		return s.list(SnapshotStages, q, nil)
+	case len(parts) == 2 && parts[0] == "stages":
+		return s.item(SnapshotStages, parts[1])
+	case len(parts) == 1 && parts[0] == "dealFields":
+		return s.list(SnapshotDealFields, q, nil)
+	case len(parts) == 2 && parts[0] == "dealFields":
+		return s.item(SnapshotDealFields, parts[1])
+	case len(parts) == 1 && parts[0] == "personFields":
+		return s.list(SnapshotPersonFields, q, nil)
+	case len(parts) == 1 && parts[0] == "organizationFields":
+		return s.list(SnapshotOrganizationFields, q, nil)
+	}
+
+	return nil, fmt.Errorf("%s: %w", endpoint, ErrOffline)
+}
+
+// item answers a request for a single object
+func (s *snapshotServer) item(entity, id string) (*http.Response, error) {
+	recs, err := s.entity(entity)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s id '%s'", entity, id)
+	}
+	data, e := recs[n]
+	if !e {
+		return snapshotResponse(http.StatusNotFound, map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("%s %d not found in snapshot", entity, n),
+			"data":    nil,
+		})
+	}
+	return snapshotResponse(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// list answers a paginated list request, the records are ordered by ID
+func (s *snapshotServer) list(entity string, q url.Values, match func(map[string]interface{}) bool) (*http.Response, error) {
+	matching, err := s.matching(entity, match)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(q, matching)
+}
+
+// pipelineDeals answers the deals of a pipeline. Unlike the deals endpoint, which the snapshot
+// is loaded from, this endpoint references persons, organizations and users by id only.
+func (s *snapshotServer) pipelineDeals(q url.Values, pipelineID string) (*http.Response, error) {
+	deals, err := s.matching(SnapshotDeals, matchField("pipeline_id", pipelineID))
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range deals {
+		deals[i], err = flattenRefs(d, "person_id", "org_id", "user_id")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paginate(q, deals)
+}
+
+// flattenRefs replaces the given reference objects of a record by their value
+func flattenRefs(data json.RawMessage, fields ...string) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		v := bytes.TrimSpace(obj[f])
+		if len(v) == 0 || v[0] != '{' {
+			continue
+		}
+		var ref struct {
+			Value json.RawMessage `json:"value"`
+		}
+		err = json.Unmarshal(v, &ref)
+		if err != nil {
+			return nil, err
+		}
+		obj[f] = ref.Value
+		if len(ref.Value) == 0 {
+			obj[f] = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// matching returns the records of an entity accepted by match, ordered by id
+func (s *snapshotServer) matching(entity string, match func(map[string]interface{}) bool) ([]json.RawMessage, error) {
+	recs, err := s.entity(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(recs))
+	for id := range recs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	matching := []json.RawMessage{}
+	for _, id := range ids {
+		if match != nil {
+			var obj map[string]interface{}
+			err = json.Unmarshal(recs[id], &obj)
+			if err != nil {
+				return nil, err
+			}
+			if !match(obj) {
+				continue
+			}
+		}
+		matching = append(matching, recs[id])
+	}
+	return matching, nil
+}
+
+// dealUpdates answers the updates of a deal from SnapshotDealUpdates
+func (s *snapshotServer) dealUpdates(endpoint, id string, q url.Values) (*http.Response, error) {
+	if !s.snapshot.Has(SnapshotDealUpdates) {
+		return nil, fmt.Errorf("%s: snapshot without deal updates %w", endpoint, ErrOffline)
+	}
+	recs, err := s.entity(SnapshotDealUpdates)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deal id '%s'", id)
+	}
+	var rec struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if data, e := recs[n]; e {
+		err = json.Unmarshal(data, &rec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paginate(q, rec.Data)
+}
+
+// paginate answers a list request with the requested page of the items
+func paginate(q url.Values, matching []json.RawMessage) (*http.Response, error) {
+	start, _ := strconv.Atoi(q.Get("start"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	if start > len(matching) {
+		start = len(matching)
+	}
+	end := start + limit
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	pagination := map[string]interface{}{
+		"start":                    start,
+		"limit":                    limit,
+		"more_items_in_collection": end < len(matching),
+	}
+	if end < len(matching) {
+		pagination["next_start"] = end
+	}
+
+	var data interface{} = matching[start:end]
+	if end == start {
+		data = nil
+	}
+	return snapshotResponse(http.StatusOK, map[string]interface{}{
+		"success":         true,
+		"data":            data,
+		"additional_data": map[string]interface{}{"pagination": pagination},
+	})
+}
+
+func snapshotResponse(status int, body interface{}) (*http.Response, error) {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(buf)),
+		ContentLength: int64(len(buf)),
+	}, nil
+}
+
+// matchField matches objects whose field, or the id of the referenced object, equals value
+func matchField(field, value string) func(map[string]interface{}) bool {
+	return func(obj map[string]interface{}) bool {
+		v := obj[field]
+		if ref, ok := v.(map[string]interface{}); ok {
+			v = ref["value"]
+			if v == nil {
+				v = ref["id"]
+			}
+		}
+		switch v := v.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64) == value
+		case string:
+			return v == value
+		case bool:
+			return (v && value == "1") || (!v && value == "0")
+		}
+		return false
+	}
+}
+
+// activityFilter implements the parameters of ActivityFilter
+func activityFilter(q url.Values) func(map[string]interface{}) bool {
+	var preds []func(map[string]interface{}) bool
+	if id := q.Get("user_id"); id != "" && id != "0" {
+		preds = append(preds, matchField("user_id", id))
+	}
+	if done := q.Get("done"); done != "" {
+		preds = append(preds, matchField("done", done))
+	}
+	if types := q.Get("type"); types != "" {
+		allowed := make(map[string]bool)
+		for _, t := range strings.Split(types, ",") {
+			allowed[t] = true
+		}
+		preds = append(preds, func(obj map[string]interface{}) bool {
+			t, _ := obj["type"].(string)
+			return allowed[t]
+		})
+	}
+	startDate, endDate := q.Get("start_date"), q.Get("end_date")
+	if startDate != "" || endDate != "" {
+		preds = append(preds, func(obj map[string]interface{}) bool {
+			due, _ := obj["due_date"].(string)
+			return (startDate == "" || due >= startDate) && (endDate == "" || due <= endDate)
+		})
+	}
+
+	return func(obj map[string]interface{}) bool {
+		for _, p := range preds {
+			if !p(obj) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,84 @@
+package pipedrive_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/pipedrivetest"
+)
+
+func TestPipelineChangesFromSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+	must(srv.Seed(pipedrivetest.Pipelines, pipedrivetest.Object{"id": 1, "name": "Vertrieb"}))
+	must(srv.Seed(pipedrivetest.Stages,
+		pipedrivetest.Object{"id": 1, "name": "Lead", "pipeline_id": 1, "order_nr": 1},
+		pipedrivetest.Object{"id": 2, "name": "Angebot", "pipeline_id": 1, "order_nr": 2},
+	))
+	// the deals endpoint the snapshot is loaded from sends references as objects
+	for _, id := range []int{1, 2} {
+		must(srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{
+			"id":        id,
+			"title":     fmt.Sprint("Deal ", id),
+			"stage_id":  1,
+			"person_id": pipedrivetest.Object{"value": 5, "name": "Person"},
+			"org_id":    pipedrivetest.Object{"value": 7, "name": "Organization"},
+			"user_id":   pipedrivetest.Object{"id": 3, "value": 3, "name": "User"},
+		}))
+	}
+
+	pd, err := pipedrive.NewAPI(srv.Options()...)
+	must(err)
+	res := make(chan pipedrive.GenericResponse, 1)
+	must(pd.PutGeneric(fmt.Sprintf(pd.Endpoints.Deal, 1), strings.NewReader(`{"stage_id":2}`), res))
+	if r := <-res; !r.Success {
+		t.Fatalf("moving deal 1 failed: %s", r.Error)
+	}
+
+	sync, err := pd.NewSync(dir)
+	must(err)
+	must(sync.Run())
+
+	offline, err := pipedrive.NewAPI(pipedrive.FromSnapshot(dir))
+	must(err)
+	deals, err := offline.FetchDealsFromPipeline(1, 0)
+	must(err)
+	if len(deals) != 2 {
+		t.Fatalf("expected 2 deals, got %d", len(deals))
+	}
+	for _, d := range deals {
+		if d.Person != 5 || d.Organization != 7 {
+			t.Errorf("deal %d: expected person 5 and organization 7, got %d and %d", d.ID, d.Person, d.Organization)
+		}
+	}
+
+	stages, err := offline.RetrieveStagesForPipeline(1)
+	must(err)
+	changes, err := offline.FetchPipelineChanges(deals, stages)
+	must(err)
+	phases := map[int]string{}
+	for _, cr := range changes {
+		p := cr.Phases()
+		phases[cr.Deal.ID] = strings.Join(p, " ")
+	}
+	if phases[1] != "Lead Angebot" || phases[2] != "Lead" {
+		t.Errorf("unexpected phases %v", phases)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -26,6 +28,33 @@ var defaultEndpoints = endpoints{
 	DealFields:     "https://api.pipedrive.com/v1/dealFields",
 	DealField:      "https://api.pipedrive.com/v1/dealFields/%d",
 	Organization:   "https://api.pipedrive.com/v1/organizations/%d",
+	Activities:     "https://api.pipedrive.com/v1/activities",
+	Activity:       "https://api.pipedrive.com/v1/activities/%d",
+	ActivityTypes:  "https://api.pipedrive.com/v1/activityTypes",
+	Notes:          "https://api.pipedrive.com/v1/notes",
+	Note:           "https://api.pipedrive.com/v1/notes/%d",
+	Products:       "https://api.pipedrive.com/v1/products",
+	Product:        "https://api.pipedrive.com/v1/products/%d",
+	ProductSearch:  "https://api.pipedrive.com/v1/products/search",
+	DealProducts:   "https://api.pipedrive.com/v1/deals/%d/products",
+	DealProduct:    "https://api.pipedrive.com/v1/deals/%d/products/%d",
+	Users:          "https://api.pipedrive.com/v1/users",
+	User:           "https://api.pipedrive.com/v1/users/%d",
+	CurrentUser:    "https://api.pipedrive.com/v1/users/me",
+	UserFind:       "https://api.pipedrive.com/v1/users/find",
+	AllDeals:       "https://api.pipedrive.com/v1/deals",
+	Leads:          "https://api.pipedrive.com/v1/leads",
+	Lead:           "https://api.pipedrive.com/v1/leads/%s",
+	LeadLabels:     "https://api.pipedrive.com/v1/leadLabels",
+	LeadLabel:      "https://api.pipedrive.com/v1/leadLabels/%s",
+	Webhooks:       "https://api.pipedrive.com/v1/webhooks",
+	Webhook:        "https://api.pipedrive.com/v1/webhooks/%d",
+	Recents:        "https://api.pipedrive.com/v1/recents",
+	Persons:        "https://api.pipedrive.com/v1/persons",
+	Organizations:  "https://api.pipedrive.com/v1/organizations",
+
+	OrganizationFields: "https://api.pipedrive.com/v1/organizationFields",
+	PersonFields:       "https://api.pipedrive.com/v1/personFields",
 }
 
 func LogURLs(a *API) error {
@@ -94,7 +123,7 @@ func (a *API) endpointFuncWithClient(get getEndpointFunc) getEndpointFunc {
 	}
 }
 
-func (a *API) requestEndpointFuncWithClient(doer func(req *http.Request) (*http.Response, error), method string) putEndpointFunc {
+func (a *API) requestEndpointFuncWithClient(doer func(req *http.Request) (*http.Response, error), method string) sendEndpointFunc {
 	return func(endpoint string, data io.Reader) (*http.Response, error) {
 		u, err := url.Parse(endpoint)
 		if err != nil {
@@ -108,6 +137,9 @@ func (a *API) requestEndpointFuncWithClient(doer func(req *http.Request) (*http.
 		if err != nil {
 			return nil, err
 		}
+		if data != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 		return doer(req)
 	}
 }
@@ -119,6 +151,9 @@ func HTTPFetcher(a *API) error {
 
 	client := http.Client{}
 	a.putEndpoint = a.requestEndpointFuncWithClient(client.Do, "PUT")
+	a.postEndpoint = a.requestEndpointFuncWithClient(client.Do, "POST")
+	a.patchEndpoint = a.requestEndpointFuncWithClient(client.Do, "PATCH")
+	a.deleteEndpoint = a.requestEndpointFuncWithClient(client.Do, "DELETE")
 	return nil
 }
 
@@ -131,6 +166,35 @@ func HTTPFetcherWithTimeout(timeout time.Duration) Option {
 		}
 		a.getEndpoint = a.endpointFuncWithClient(client.Get)
 		a.putEndpoint = a.requestEndpointFuncWithClient(client.Do, "PUT")
+		a.postEndpoint = a.requestEndpointFuncWithClient(client.Do, "POST")
+		a.patchEndpoint = a.requestEndpointFuncWithClient(client.Do, "PATCH")
+		a.deleteEndpoint = a.requestEndpointFuncWithClient(client.Do, "DELETE")
+		return nil
+	}
+}
+
+// defaultBaseURL is the prefix of all default endpoints
+const defaultBaseURL = "https://api.pipedrive.com"
+
+// WithBaseURL sends all requests to the given server instead of api.pipedrive.com,
+// e.g. a proxy or the fake server of the pipedrivetest package
+func WithBaseURL(baseURL string) Option {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return func(a *API) error {
+		_, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		a.afterInit = append(a.afterInit, func(a *API) error {
+			v := reflect.ValueOf(&a.Endpoints).Elem()
+			for i := 0; i < v.NumField(); i++ {
+				f := v.Field(i)
+				if strings.HasPrefix(f.String(), defaultBaseURL) {
+					f.SetString(baseURL + strings.TrimPrefix(f.String(), defaultBaseURL))
+				}
+			}
+			return nil
+		})
 		return nil
 	}
 }
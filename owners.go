@@ -0,0 +1,85 @@
+package pipedrive
+
+import (
+	"sort"
+	"time"
+)
+
+// OwnerStats summarizes the closed deals of a single deal owner
+type OwnerStats struct {
+	OwnerID   int     `json:"owner_id"`
+	Owner     string  `json:"owner"`
+	Won       int     `json:"won"`
+	Lost      int     `json:"lost"`
+	WinRate   float64 `json:"win_rate"`
+	WonValue  float64 `json:"won_value"`
+	AvgValue  float64 `json:"avg_value"`
+	CycleDays float64 `json:"cycle_days"`
+	// Velocity is the value expected to be won per day: deals × value × win rate / cycle length
+	Velocity float64 `json:"velocity"`
+}
+
+type OwnerLeaderboard []OwnerStats
+
+// NewOwnerLeaderboard computes the stats of all owners for deals closed within [from, to).
+// A zero time leaves that end open. The result is ordered by velocity.
+func NewOwnerLeaderboard(deals DealRefs, from, to time.Time) OwnerLeaderboard {
+	type acc struct {
+		OwnerStats
+		cycle float64
+		value float64
+	}
+	byOwner := make(map[int]*acc)
+	for _, d := range deals {
+		var closed *Time
+		switch d.Status {
+		case "won":
+			closed = d.WonAt
+		case "lost":
+			closed = d.LostAt
+		}
+		if closed == nil {
+			continue
+		}
+		if !from.IsZero() && closed.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !closed.Before(to) {
+			continue
+		}
+
+		a, e := byOwner[d.User.ID]
+		if !e {
+			a = &acc{OwnerStats: OwnerStats{OwnerID: d.User.ID, Owner: d.User.Name}}
+			byOwner[d.User.ID] = a
+		}
+		a.cycle += closed.Sub(d.Added.Time).Hours() / 24
+		a.value += d.Value
+		if d.Status == "won" {
+			a.Won++
+			a.WonValue += d.Value
+		} else {
+			a.Lost++
+		}
+	}
+
+	lb := OwnerLeaderboard{}
+	for _, a := range byOwner {
+		s := a.OwnerStats
+		closed := float64(s.Won + s.Lost)
+		s.WinRate = float64(s.Won) / closed
+		s.AvgValue = a.value / closed
+		s.CycleDays = a.cycle / closed
+		if s.CycleDays > 0 {
+			s.Velocity = closed * s.AvgValue * s.WinRate / s.CycleDays
+		}
+		lb = append(lb, s)
+	}
+	sort.SliceStable(lb, func(i, j int) bool {
+		if lb[i].Velocity != lb[j].Velocity {
+			return lb[i].Velocity > lb[j].Velocity
+		}
+		return lb[i].Owner < lb[j].Owner
+	})
+	return lb
+}
@@ -0,0 +1,381 @@
+package pipedrivetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("api_token") != Token {
+		writeJSON(w, http.StatusUnauthorized, Object{"success": false, "error": "unauthorized access"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"), "/"), "/")
+	collection := parts[0]
+	if len(parts) == 1 && collection == "recents" && r.Method == http.MethodGet {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.recents(w, r.URL.Query())
+		return
+	}
+	if _, e := s.objects[collection]; !e {
+		writeError(w, http.StatusNotFound, "unknown endpoint %s", r.URL.Path)
+		return
+	}
+
+	id := 0
+	if len(parts) > 1 {
+		var err error
+		id, err = strconv.Atoi(parts[1])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id '%s'", parts[1])
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.list(w, q, collection)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		s.create(w, r, collection)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		s.get(w, collection, id)
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		s.update(w, r, collection, id)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		s.remove(w, collection, id)
+	case len(parts) == 3 && r.Method == http.MethodGet && collection == Deals && parts[2] == "updates":
+		paginate(w, q, s.updates[id])
+	case len(parts) == 3 && r.Method == http.MethodGet && collection == Deals && parts[2] == "activities":
+		q.Set("deal_id", parts[1])
+		s.list(w, q, Activities)
+	case len(parts) == 3 && r.Method == http.MethodGet && collection == Pipelines && parts[2] == "deals":
+		q.Set("pipeline_id", parts[1])
+		s.list(w, q, Deals)
+	default:
+		writeError(w, http.StatusNotFound, "unknown endpoint %s %s", r.Method, r.URL.Path)
+	}
+}
+
+// list answers a paginated list request, supporting the query parameters used by the library
+func (s *Server) list(w http.ResponseWriter, q url.Values, collection string) {
+	var filter map[int]bool
+	if id, _ := strconv.Atoi(q.Get("filter_id")); id > 0 {
+		var e bool
+		filter, e = s.filterDeals[id]
+		if !e {
+			writeError(w, http.StatusNotFound, "filter %d not found", id)
+			return
+		}
+	}
+
+	matching := []Object{}
+	for _, id := range s.ids(collection) {
+		o := s.objects[collection][id]
+		if filter != nil && !filter[id] {
+			continue
+		}
+		if !matches(o, q) {
+			continue
+		}
+		matching = append(matching, o)
+	}
+	paginate(w, q, matching)
+}
+
+// matches checks the equality filters of list requests
+func matches(o Object, q url.Values) bool {
+	for _, k := range []string{"pipeline_id", "deal_id", "stage_id", "status", "type"} {
+		v := q.Get(k)
+		if v == "" || (k == "status" && v == "all_not_deleted") {
+			continue
+		}
+		switch ov := o[k].(type) {
+		case string:
+			if k == "type" {
+				if !strings.Contains(","+v+",", ","+ov+",") {
+					return false
+				}
+			} else if ov != v {
+				return false
+			}
+		default:
+			if strconv.Itoa(intValue(ov)) != v {
+				return false
+			}
+		}
+	}
+	if v := q.Get("user_id"); v != "" && v != "0" && strconv.Itoa(intValue(o["user_id"])) != v {
+		return false
+	}
+	if v := q.Get("done"); v != "" {
+		done, _ := o["done"].(bool)
+		if done != (v == "1") {
+			return false
+		}
+	}
+	due, _ := o["due_date"].(string)
+	if v := q.Get("start_date"); v != "" && due < v {
+		return false
+	}
+	if v := q.Get("end_date"); v != "" && due > v {
+		return false
+	}
+	return true
+}
+
+// recentItems maps the item types of the recents endpoint to collections
+var recentItems = map[string]string{
+	"deal":         Deals,
+	"person":       Persons,
+	"organization": Organizations,
+	"activity":     Activities,
+	"note":         Notes,
+	"stage":        Stages,
+	"pipeline":     Pipelines,
+}
+
+// recents answers the changes since since_timestamp ordered by their update_time.
+// Deleted objects are reported with deleted true and active_flag false.
+func (s *Server) recents(w http.ResponseWriter, q url.Values) {
+	since := q.Get("since_timestamp")
+	if since == "" {
+		writeError(w, http.StatusBadRequest, "since_timestamp is required")
+		return
+	}
+
+	changes := []Object{}
+	for _, item := range strings.Split(q.Get("items"), ",") {
+		collection, e := recentItems[item]
+		if !e {
+			continue
+		}
+		objects := []Object{}
+		for _, id := range s.ids(collection) {
+			objects = append(objects, s.objects[collection][id])
+		}
+		for _, o := range append(objects, s.deleted[collection]...) {
+			if ut, _ := o["update_time"].(string); ut >= since {
+				changes = append(changes, Object{"item": item, "id": intValue(o["id"]), "data": o})
+			}
+		}
+	}
+	updateTime := func(i int) string {
+		ut, _ := changes[i]["data"].(Object)["update_time"].(string)
+		return ut
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return updateTime(i) < updateTime(j)
+	})
+	paginate(w, q, changes)
+}
+
+func paginate(w http.ResponseWriter, q url.Values, objects []Object) {
+	start, _ := strconv.Atoi(q.Get("start"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	if start > len(objects) {
+		start = len(objects)
+	}
+	end := start + limit
+	if end > len(objects) {
+		end = len(objects)
+	}
+
+	pagination := Object{
+		"start":                    start,
+		"limit":                    limit,
+		"more_items_in_collection": end < len(objects),
+	}
+	if end < len(objects) {
+		pagination["next_start"] = end
+	}
+
+	var data interface{} = objects[start:end]
+	if start == end {
+		data = nil
+	}
+	writeJSON(w, http.StatusOK, Object{
+		"success":         true,
+		"data":            data,
+		"additional_data": Object{"pagination": pagination},
+	})
+}
+
+func (s *Server) get(w http.ResponseWriter, collection string, id int) {
+	o, e := s.objects[collection][id]
+	if !e {
+		writeError(w, http.StatusNotFound, "%s %d not found", collection, id)
+		return
+	}
+	writeJSON(w, http.StatusOK, Object{"success": true, "data": o})
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, collection string) {
+	var obj Object
+	err := json.NewDecoder(r.Body).Decode(&obj)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	delete(obj, "id")
+	if !s.validStage(w, collection, obj) {
+		return
+	}
+	obj = s.insert(collection, obj)
+
+	if collection == Deals {
+		id := intValue(obj["id"])
+		s.updates[id] = append(s.updates[id], s.dealUpdate("add", nil))
+	}
+	writeJSON(w, http.StatusCreated, Object{"success": true, "data": obj})
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, collection string, id int) {
+	o, e := s.objects[collection][id]
+	if !e {
+		writeError(w, http.StatusNotFound, "%s %d not found", collection, id)
+		return
+	}
+
+	var changes Object
+	err := json.NewDecoder(r.Body).Decode(&changes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %v", err)
+		return
+	}
+	delete(changes, "id")
+	if !s.validStage(w, collection, changes) {
+		return
+	}
+
+	before := copyObject(o)
+	for k, v := range changes {
+		o[k] = v
+	}
+	o["update_time"] = s.Now().UTC().Format(timeFormat)
+
+	if collection == Deals {
+		if _, e := changes["stage_id"]; e {
+			s.setPipeline(o)
+			o["stage_change_time"] = o["update_time"]
+		}
+		if status, _ := changes["status"].(string); status == "won" || status == "lost" {
+			o[status+"_time"] = o["update_time"]
+		}
+		s.updates[id] = append(s.updates[id], s.dealChanges(before, changes)...)
+	}
+	writeJSON(w, http.StatusOK, Object{"success": true, "data": o})
+}
+
+// validStage rejects deals referencing a stage that does not exist, like the real API
+func (s *Server) validStage(w http.ResponseWriter, collection string, obj Object) bool {
+	v, e := obj["stage_id"]
+	if collection != Deals || !e {
+		return true
+	}
+	if _, e := s.objects[Stages][intValue(v)]; !e {
+		writeError(w, http.StatusBadRequest, "stage %v not found", v)
+		return false
+	}
+	return true
+}
+
+func (s *Server) remove(w http.ResponseWriter, collection string, id int) {
+	if _, e := s.objects[collection][id]; !e {
+		writeError(w, http.StatusNotFound, "%s %d not found", collection, id)
+		return
+	}
+	delete(s.objects[collection], id)
+	s.deleted[collection] = append(s.deleted[collection], Object{
+		"id":          id,
+		"active_flag": false,
+		"deleted":     true,
+		"update_time": s.Now().UTC().Format(timeFormat),
+	})
+	if collection == Deals {
+		delete(s.updates, id)
+	}
+	writeJSON(w, http.StatusOK, Object{"success": true, "data": Object{"id": id}})
+}
+
+// dealChanges creates one update per changed field, as the flow analysis reads only the first entry
+// of a change log. Stage changes are logged as "Phase" with the stage names as values.
+func (s *Server) dealChanges(before, changes Object) []Object {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	names := make(map[string]string)
+	for _, f := range s.objects[DealFields] {
+		if key, ok := f["key"].(string); ok {
+			names[key], _ = f["name"].(string)
+		}
+	}
+
+	updates := []Object{}
+	for _, k := range keys {
+		oldValue, newValue := before[k], changes[k]
+		if k == "add_time" || k == "update_time" || fmt.Sprint(oldValue) == fmt.Sprint(newValue) {
+			continue
+		}
+		name := names[k]
+		if name == "" {
+			name = k
+		}
+		if k == "stage_id" {
+			name = "Phase"
+			oldValue = s.objects[Stages][intValue(oldValue)]["name"]
+			newValue = s.objects[Stages][intValue(newValue)]["name"]
+		}
+		updates = append(updates, s.dealUpdate("edit", Object{
+			"field_key":  k,
+			"field_name": name,
+			"old_value":  oldValue,
+			"new_value":  newValue,
+		}))
+	}
+	return updates
+}
+
+// dealUpdate creates an entry of the updates of a deal, as read by FetchDealUpdates
+func (s *Server) dealUpdate(action string, change Object) Object {
+	now := s.Now().UTC().Format(timeFormat)
+	changeLog := []Object{}
+	if change != nil {
+		changeLog = append(changeLog, change)
+	}
+	return Object{
+		"object": "dealChange",
+		"story_data": Object{
+			"action_type": action,
+			"change_log":  changeLog,
+			"add_time":    now,
+		},
+		"add_time": now,
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, Object{"success": false, "error": fmt.Sprintf(format, args...), "data": nil})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,261 @@
+// Package pipedrivetest provides an in-memory fake of the Pipedrive v1 API for integration tests.
+//
+//	srv := pipedrivetest.NewServer()
+//	defer srv.Close()
+//	srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{"id": 1, "title": "Deal", "stage_id": 1})
+//	pd, err := pipedrive.NewAPI(srv.Options()...)
+package pipedrivetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+// Token is the API token accepted by the server
+const Token = "pipedrivetest"
+
+// Collections held by the server
+const (
+	Deals         = "deals"
+	Pipelines     = "pipelines"
+	Stages        = "stages"
+	Filters       = "filters"
+	DealFields    = "dealFields"
+	PersonFields  = "personFields"
+	OrgFields     = "organizationFields"
+	Organizations = "organizations"
+	Persons       = "persons"
+	Activities    = "activities"
+	Notes         = "notes"
+)
+
+var collections = []string{Deals, Pipelines, Stages, Filters, DealFields, PersonFields, OrgFields, Organizations, Persons, Activities, Notes}
+
+// timeFormat is the format of timestamps in the v1 API
+const timeFormat = "2006-01-02 15:04:05"
+
+// Object is a JSON object as sent and received by the API
+type Object map[string]interface{}
+
+// Server is a fake Pipedrive API. Reads and writes work on the seeded state.
+type Server struct {
+	*httptest.Server
+
+	// Now returns the time used for add_time, update_time and deal updates, defaults to time.Now
+	Now func() time.Time
+
+	mu          sync.Mutex
+	objects     map[string]map[int]Object
+	updates     map[int][]Object
+	filterDeals map[int]map[int]bool
+	// deleted holds the tombstones of deleted objects, reported by the recents endpoint
+	deleted map[string][]Object
+	lastID  int
+}
+
+// NewServer starts a server without any objects
+func NewServer() *Server {
+	s := &Server{
+		Now:         time.Now,
+		objects:     make(map[string]map[int]Object),
+		updates:     make(map[int][]Object),
+		filterDeals: make(map[int]map[int]bool),
+		deleted:     make(map[string][]Object),
+	}
+	for _, c := range collections {
+		s.objects[c] = make(map[int]Object)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Options returns the options for an API client talking to the server
+func (s *Server) Options() []pipedrive.Option {
+	return []pipedrive.Option{
+		pipedrive.HTTPFetcher,
+		pipedrive.FixedToken(Token),
+		pipedrive.WithBaseURL(s.URL),
+	}
+}
+
+// Seed adds objects to a collection. Objects without id get the next free one,
+// add_time and update_time default to Now, deals are open and in the pipeline of their stage.
+// Won and lost deals get a won_time or lost_time of their update_time unless given.
+func (s *Server) Seed(collection string, objects ...Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, e := s.objects[collection]; !e {
+		return fmt.Errorf("unknown collection '%s'", collection)
+	}
+	for _, o := range objects {
+		obj, err := normalize(o)
+		if err != nil {
+			return err
+		}
+		s.insert(collection, obj)
+	}
+	return nil
+}
+
+// SetFilter makes the filter with the given id match exactly the given deals
+func (s *Server) SetFilter(filterID int, dealIDs ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make(map[int]bool)
+	for _, id := range dealIDs {
+		ids[id] = true
+	}
+	s.filterDeals[filterID] = ids
+}
+
+// AddUpdate appends an entry to the updates of a deal, see pipedrive.DealUpdate
+func (s *Server) AddUpdate(dealID int, update Object) error {
+	obj, err := normalize(update)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates[dealID] = append(s.updates[dealID], obj)
+	return nil
+}
+
+// Object returns a copy of the object with the given id
+func (s *Server) Object(collection string, id int) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, e := s.objects[collection][id]
+	if !e {
+		return nil, false
+	}
+	return copyObject(o), true
+}
+
+// Objects returns copies of all objects of a collection ordered by id
+func (s *Server) Objects(collection string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := []Object{}
+	for _, id := range s.ids(collection) {
+		res = append(res, copyObject(s.objects[collection][id]))
+	}
+	return res
+}
+
+// Updates returns the updates of a deal, including those recorded for writes
+func (s *Server) Updates(dealID int) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]Object, 0, len(s.updates[dealID]))
+	for _, u := range s.updates[dealID] {
+		res = append(res, copyObject(u))
+	}
+	return res
+}
+
+// normalize turns an object into its JSON representation, numbers become float64
+func normalize(o Object) (Object, error) {
+	buf, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var obj Object
+	err = json.Unmarshal(buf, &obj)
+	return obj, err
+}
+
+func copyObject(o Object) Object {
+	c, _ := normalize(o)
+	return c
+}
+
+// insert adds an object, filling in the defaults. The lock must be held.
+func (s *Server) insert(collection string, obj Object) Object {
+	id := intValue(obj["id"])
+	if id == 0 {
+		s.lastID++
+		id = s.lastID
+	} else if id > s.lastID {
+		s.lastID = id
+	}
+	obj["id"] = id
+
+	now := s.Now().UTC().Format(timeFormat)
+	for _, k := range []string{"add_time", "update_time"} {
+		if _, e := obj[k]; !e {
+			obj[k] = now
+		}
+	}
+
+	switch collection {
+	case Deals:
+		if _, e := obj["status"]; !e {
+			obj["status"] = "open"
+		}
+		if status, _ := obj["status"].(string); status == "won" || status == "lost" {
+			if _, e := obj[status+"_time"]; !e {
+				obj[status+"_time"] = obj["update_time"]
+			}
+		}
+		s.setPipeline(obj)
+	case Activities:
+		if _, e := obj["done"]; !e {
+			obj["done"] = false
+		}
+	}
+	if _, e := obj["active_flag"]; !e {
+		obj["active_flag"] = true
+	}
+
+	s.objects[collection][id] = obj
+	return obj
+}
+
+// setPipeline sets the pipeline of a deal from its stage. The lock must be held.
+func (s *Server) setPipeline(deal Object) {
+	if stage, e := s.objects[Stages][intValue(deal["stage_id"])]; e {
+		deal["pipeline_id"] = stage["pipeline_id"]
+	}
+}
+
+// ids returns the ids of a collection in ascending order. The lock must be held.
+func (s *Server) ids(collection string) []int {
+	ids := make([]int, 0, len(s.objects[collection]))
+	for id := range s.objects[collection] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// intValue returns the id of a number, numeric string or referenced object
+func intValue(v interface{}) int {
+	switch v := v.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	case map[string]interface{}:
+		if id, e := v["value"]; e {
+			return intValue(id)
+		}
+		return intValue(v["id"])
+	}
+	return 0
+}
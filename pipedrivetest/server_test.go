@@ -0,0 +1,86 @@
+package pipedrivetest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/pipedrivetest"
+)
+
+func TestDealLifecycle(t *testing.T) {
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(srv.Seed(pipedrivetest.Pipelines, pipedrivetest.Object{"id": 1, "name": "Vertrieb"}))
+	must(srv.Seed(pipedrivetest.Stages,
+		pipedrivetest.Object{"id": 1, "name": "Lead", "pipeline_id": 1, "order_nr": 1},
+		pipedrivetest.Object{"id": 2, "name": "Angebot", "pipeline_id": 1, "order_nr": 2},
+	))
+	const count = 1203
+	for i := 0; i < count; i++ {
+		must(srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{"title": fmt.Sprint("Deal ", i), "stage_id": 1, "value": 100}))
+	}
+	must(srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{"title": "Won", "stage_id": 2, "status": "won"}))
+
+	pd, err := pipedrive.NewAPI(srv.Options()...)
+	must(err)
+
+	// FetchDeals pages with a limit of 500
+	refs, err := pd.FetchDeals(0)
+	must(err)
+	if len(refs) != count+1 {
+		t.Fatalf("expected %d deals, got %d", count+1, len(refs))
+	}
+	won := refs[len(refs)-1]
+	if won.Status != "won" || won.WonAt == nil {
+		t.Errorf("seeded won deal without won_time: %+v", won)
+	}
+
+	moved := refs[:50]
+	for _, d := range moved {
+		res := make(chan pipedrive.GenericResponse, 1)
+		must(pd.PutGeneric(fmt.Sprintf(pd.Endpoints.Deal, d.ID), strings.NewReader(`{"stage_id":2}`), res))
+		if r := <-res; !r.Success {
+			t.Fatalf("moving deal %d failed: %s", d.ID, r.Error)
+		}
+	}
+
+	res := make(chan pipedrive.GenericResponse, 1)
+	must(pd.PutGeneric(fmt.Sprintf(pd.Endpoints.Deal, refs[50].ID), strings.NewReader(`{"stage_id":99}`), res))
+	if r := <-res; r.Success {
+		t.Error("moving a deal to an unknown stage succeeded")
+	}
+
+	stages, err := pd.RetrieveStagesForPipeline(1)
+	must(err)
+	deals := make([]pipedrive.Deal, 0, len(moved)+1)
+	for _, d := range append(moved, refs[50]) {
+		deals = append(deals, pipedrive.Deal{ID: d.ID, Added: d.Added, Status: d.Status})
+	}
+	changes, err := pd.FetchPipelineChanges(deals, stages)
+	must(err)
+	if len(changes) != len(deals) {
+		t.Fatalf("expected %d pipeline changes, got %d", len(deals), len(changes))
+	}
+	for _, cr := range changes {
+		phases := cr.Phases()
+		last := phases[len(phases)-1]
+		if cr.Deal.ID == refs[50].ID {
+			if last != "Lead" {
+				t.Errorf("deal %d: expected to stay in Lead, got %v", cr.Deal.ID, phases)
+			}
+			continue
+		}
+		if last != "Angebot" {
+			t.Errorf("deal %d: expected to end in Angebot, got %v", cr.Deal.ID, phases)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ProductPrice is the price of a product in a single currency
+type ProductPrice struct {
+	ID           int     `json:"id"`
+	ProductID    int     `json:"product_id"`
+	Price        float64 `json:"price"`
+	Currency     string  `json:"currency"`
+	Cost         float64 `json:"cost"`
+	OverheadCost float64 `json:"overhead_cost"`
+}
+
+// Product models an entry of the product catalog
+type Product struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Code        string         `json:"code"`
+	Description string         `json:"description"`
+	Unit        string         `json:"unit"`
+	Tax         float64        `json:"tax"`
+	ActiveFlag  bool           `json:"active_flag"`
+	Selectable  bool           `json:"selectable"`
+	VisibleTo   string         `json:"visible_to"`
+	Prices      []ProductPrice `json:"prices"`
+	AddTime     *Time          `json:"add_time"`
+	UpdateTime  *Time          `json:"update_time"`
+}
+
+// Price returns the price of the product in the given currency
+func (p Product) Price(currency string) (ProductPrice, bool) {
+	for _, pp := range p.Prices {
+		if pp.Currency == currency {
+			return pp, true
+		}
+	}
+	return ProductPrice{}, false
+}
+
+// FetchProducts returns the whole product catalog
+func (pd *API) FetchProducts() ([]Product, error) {
+	products := []Product{}
+	err := pd.getAllJSON(pd.Endpoints.Products, nil, func(data json.RawMessage) error {
+		var page []Product
+		err := json.Unmarshal(data, &page)
+		products = append(products, page...)
+		return err
+	})
+	return products, err
+}
+
+// FetchProduct returns the product with the given id
+func (pd *API) FetchProduct(id int) (Product, error) {
+	var p Product
+	err := pd.getJSON(fmt.Sprintf(pd.Endpoints.Product, id), &p)
+	return p, err
+}
+
+// SearchProducts searches products by name and code.
+// The results only carry the fields returned by the search, use FetchProduct for the details.
+func (pd *API) SearchProducts(term string) ([]Product, error) {
+	products := []Product{}
+	values := url.Values{}
+	values.Set("term", term)
+	err := pd.getAllJSON(pd.Endpoints.ProductSearch, values, func(data json.RawMessage) error {
+		var page struct {
+			Items []struct {
+				Item Product `json:"item"`
+			} `json:"items"`
+		}
+		err := json.Unmarshal(data, &page)
+		for _, i := range page.Items {
+			products = append(products, i.Item)
+		}
+		return err
+	})
+	return products, err
+}
+
+// DealProduct is a product attached to a deal
+type DealProduct struct {
+	// ID identifies the attachment, not the product
+	ID          int     `json:"id"`
+	DealID      int     `json:"deal_id"`
+	ProductID   int     `json:"product_id"`
+	Name        string  `json:"name"`
+	ItemPrice   float64 `json:"item_price"`
+	Quantity    float64 `json:"quantity"`
+	Discount    float64 `json:"discount_percentage"`
+	Tax         float64 `json:"tax"`
+	Duration    float64 `json:"duration"`
+	Sum         float64 `json:"sum"`
+	Currency    string  `json:"currency"`
+	Comments    string  `json:"comments"`
+	EnabledFlag bool    `json:"enabled_flag"`
+	AddTime     *Time   `json:"add_time"`
+}
+
+type DealProducts []DealProduct
+
+// DealProductParams describes a line item to be attached to a deal or updated.
+// ProductID is only used when attaching, attaching requires ItemPrice and Quantity.
+// Fields left nil are not sent, so an update only changes the given fields.
+type DealProductParams struct {
+	ProductID int      `json:"product_id,omitempty"`
+	ItemPrice *float64 `json:"item_price,omitempty"`
+	Quantity  *float64 `json:"quantity,omitempty"`
+	Discount  *float64 `json:"discount_percentage,omitempty"`
+	Tax       *float64 `json:"tax,omitempty"`
+	Comments  string   `json:"comments,omitempty"`
+	Enabled   *bool    `json:"enabled_flag,omitempty"`
+}
+
+// Float returns a pointer to the given value, for the optional fields of DealProductParams
+func Float(f float64) *float64 {
+	return &f
+}
+
+// FetchDealProducts returns the line items of the given deal
+func (pd *API) FetchDealProducts(dealID int) (DealProducts, error) {
+	products := DealProducts{}
+	err := pd.getAllJSON(fmt.Sprintf(pd.Endpoints.DealProducts, dealID), nil, func(data json.RawMessage) error {
+		var page DealProducts
+		err := json.Unmarshal(data, &page)
+		products = append(products, page...)
+		return err
+	})
+	return products, err
+}
+
+// LoadDealProducts fetches the line items of the deal into its Products field
+func (pd *API) LoadDealProducts(deal *Deal) error {
+	if deal.ProductsCount == 0 {
+		deal.Products = DealProducts{}
+		return nil
+	}
+	products, err := pd.FetchDealProducts(deal.ID)
+	if err != nil {
+		return err
+	}
+	deal.Products = products
+	return nil
+}
+
+// AttachDealProduct adds a line item to the deal
+func (pd *API) AttachDealProduct(dealID int, params DealProductParams) (DealProduct, error) {
+	var dp DealProduct
+	err := pd.sendJSON(pd.postEndpoint, fmt.Sprintf(pd.Endpoints.DealProducts, dealID), params, &dp)
+	return dp, err
+}
+
+// UpdateDealProduct changes a line item of the deal, identified by the attachment id
+func (pd *API) UpdateDealProduct(dealID, attachmentID int, params DealProductParams) (DealProduct, error) {
+	params.ProductID = 0
+	var dp DealProduct
+	err := pd.sendJSON(pd.putEndpoint, fmt.Sprintf(pd.Endpoints.DealProduct, dealID, attachmentID), params, &dp)
+	return dp, err
+}
+
+// RemoveDealProduct deletes a line item from the deal, identified by the attachment id
+func (pd *API) RemoveDealProduct(dealID, attachmentID int) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.DealProduct, dealID, attachmentID), nil, nil)
+}
@@ -0,0 +1,250 @@
+package pipedrive
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrNoFixture is returned in replay mode for requests that were not recorded
+var ErrNoFixture = errors.New("no recorded fixture")
+
+// Fixture is a recorded request/response pair. The API token is never part of it.
+type Fixture struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Status      int             `json:"status"`
+	Header      http.Header     `json:"header,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyText    string          `json:"body_text,omitempty"`
+}
+
+// fixtureBook names the fixture files. Repeated identical requests are numbered,
+// so a read after a write is replayed with the response recorded after the write.
+type fixtureBook struct {
+	dir string
+
+	mu    sync.Mutex
+	count map[string]int
+}
+
+var fixtureNameChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// next returns the file of the next occurrence of the request
+func (b *fixtureBook) next(method, endpoint string, body []byte) (string, string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	q.Del("api_token")
+	u.RawQuery = q.Encode()
+	normalized := u.String()
+
+	// the host is left out, so fixtures can be replayed against any base URL
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %s\n", method, u.RequestURI())
+	h.Write(body)
+	key := hex.EncodeToString(h.Sum(nil))[:12]
+
+	path := u.Path
+	if i := strings.Index(path, "/v1/"); i >= 0 {
+		path = path[i+len("/v1/"):]
+	}
+	name := strings.Trim(fixtureNameChars.ReplaceAllString(path, "-"), "-")
+
+	b.mu.Lock()
+	n := b.count[key]
+	b.count[key]++
+	b.mu.Unlock()
+
+	return filepath.Join(b.dir, fmt.Sprintf("%s-%s-%s-%d.json", method, name, key, n)), normalized, nil
+}
+
+// readBody drains an optional request body, returning a fresh reader for it
+func readBody(data io.Reader) ([]byte, io.Reader, error) {
+	if data == nil {
+		return nil, nil, nil
+	}
+	buf, err := ioutil.ReadAll(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf, bytes.NewReader(buf), nil
+}
+
+// RecordTo saves every request and response to a fixture file in dir, to be served by ReplayFrom.
+// It wraps the fetcher configured by the other options and removes the API token from the fixtures.
+func RecordTo(dir string) Option {
+	return func(a *API) error {
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+		book := &fixtureBook{dir: dir, count: make(map[string]int)}
+
+		record := func(method string, send sendEndpointFunc) sendEndpointFunc {
+			return func(endpoint string, data io.Reader) (*http.Response, error) {
+				reqBody, data, err := readBody(data)
+				if err != nil {
+					return nil, err
+				}
+				res, err := send(endpoint, data)
+				if err != nil {
+					return nil, err
+				}
+				body, err := ioutil.ReadAll(res.Body)
+				res.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+				file, normalized, err := book.next(method, endpoint, reqBody)
+				if err != nil {
+					return nil, err
+				}
+				f := Fixture{
+					Method: method,
+					URL:    normalized,
+					Status: res.StatusCode,
+					Header: http.Header{},
+				}
+				if ct := res.Header.Get("Content-Type"); ct != "" {
+					f.Header.Set("Content-Type", ct)
+				}
+				if len(reqBody) > 0 {
+					f.RequestBody = json.RawMessage(a.redact(reqBody))
+					if !json.Valid(f.RequestBody) {
+						f.RequestBody, _ = json.Marshal(string(f.RequestBody))
+					}
+				}
+				redacted := a.redact(body)
+				if json.Valid(redacted) {
+					f.Body = json.RawMessage(redacted)
+				} else {
+					f.BodyText = string(redacted)
+				}
+
+				buf, err := json.MarshalIndent(f, "", "  ")
+				if err != nil {
+					return nil, err
+				}
+				err = ioutil.WriteFile(file, append(buf, '\n'), 0644)
+				if err != nil {
+					return nil, err
+				}
+				return res, nil
+			}
+		}
+
+		a.afterInit = append(a.afterInit, func(a *API) error {
+			if a.getEndpoint == nil {
+				return errors.New("RecordTo needs a fetcher like HTTPFetcher")
+			}
+			get := a.getEndpoint
+			recordGet := record("GET", func(endpoint string, data io.Reader) (*http.Response, error) {
+				return get(endpoint)
+			})
+			a.getEndpoint = func(endpoint string) (*http.Response, error) {
+				return recordGet(endpoint, nil)
+			}
+			if a.putEndpoint != nil {
+				a.putEndpoint = record("PUT", a.putEndpoint)
+			}
+			if a.postEndpoint != nil {
+				a.postEndpoint = record("POST", a.postEndpoint)
+			}
+			if a.patchEndpoint != nil {
+				a.patchEndpoint = record("PATCH", a.patchEndpoint)
+			}
+			if a.deleteEndpoint != nil {
+				a.deleteEndpoint = record("DELETE", a.deleteEndpoint)
+			}
+			return nil
+		})
+		return nil
+	}
+}
+
+// ReplayFrom serves all requests from the fixtures recorded by RecordTo in dir, without the network.
+// Requests have to be made in the recorded order only when the same request was recorded repeatedly.
+// Unrecorded requests fail with ErrNoFixture.
+func ReplayFrom(dir string) Option {
+	return func(a *API) error {
+		book := &fixtureBook{dir: dir, count: make(map[string]int)}
+
+		replay := func(method string) sendEndpointFunc {
+			return func(endpoint string, data io.Reader) (*http.Response, error) {
+				a.logURL(endpoint)
+				reqBody, _, err := readBody(data)
+				if err != nil {
+					return nil, err
+				}
+				file, normalized, err := book.next(method, endpoint, reqBody)
+				if err != nil {
+					return nil, err
+				}
+				buf, err := ioutil.ReadFile(file)
+				if os.IsNotExist(err) {
+					return nil, fmt.Errorf("%s %s: %w", method, normalized, ErrNoFixture)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				var f Fixture
+				err = json.Unmarshal(buf, &f)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", file, err)
+				}
+				body := []byte(f.Body)
+				if len(body) == 0 {
+					body = []byte(f.BodyText)
+				}
+				return &http.Response{
+					Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+					StatusCode:    f.Status,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        f.Header,
+					Body:          ioutil.NopCloser(bytes.NewReader(body)),
+					ContentLength: int64(len(body)),
+				}, nil
+			}
+		}
+
+		a.Endpoints = defaultEndpoints
+		get := replay("GET")
+		a.getEndpoint = func(endpoint string) (*http.Response, error) {
+			return get(endpoint, nil)
+		}
+		a.putEndpoint = replay("PUT")
+		a.postEndpoint = replay("POST")
+		a.patchEndpoint = replay("PATCH")
+		a.deleteEndpoint = replay("DELETE")
+		return nil
+	}
+}
+
+// redact removes the API token from recorded data
+func (a *API) redact(data []byte) []byte {
+	if a.token == "" {
+		return data
+	}
+	return bytes.Replace(data, []byte(a.token), []byte("REDACTED"), -1)
+}
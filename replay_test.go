@@ -0,0 +1,101 @@
+package pipedrive_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/pipedrivetest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+	err = srv.Seed(pipedrivetest.Deals,
+		pipedrivetest.Object{"id": 1, "title": "First", "value": 100},
+		pipedrivetest.Object{"id": 2, "title": "Second", "value": 200},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type results struct {
+		Deals  pipedrive.DealRefs
+		Before pipedrive.DealRef
+		Note   pipedrive.Note
+		After  pipedrive.DealRef
+	}
+	run := func(pd *pipedrive.API) (results, error) {
+		var r results
+		var err error
+		r.Deals, err = pd.FetchDeals(0)
+		if err != nil {
+			return r, err
+		}
+		r.Before, err = pd.FetchDeal(1)
+		if err != nil {
+			return r, err
+		}
+		r.Note, err = pd.CreateNote(pipedrive.NoteParams{DealID: 1, Content: "recorded"})
+		if err != nil {
+			return r, err
+		}
+		r.After, err = pd.FetchDeal(1)
+		return r, err
+	}
+
+	recorder, err := pipedrive.NewAPI(append(srv.Options(), pipedrive.RecordTo(dir))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := run(recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Errorf("expected 4 fixtures, got %d", len(files))
+	}
+	for _, f := range files {
+		buf, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(buf), pipedrivetest.Token) {
+			t.Errorf("%s contains the API token", f)
+		}
+	}
+
+	replayer, err := pipedrive.NewAPI(pipedrive.ReplayFrom(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := run(replayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("replay differs from recording:\n%+v\n%+v", recorded, replayed)
+	}
+
+	_, err = replayer.FetchDeal(3)
+	if !errors.Is(err, pipedrive.ErrNoFixture) {
+		t.Errorf("expected ErrNoFixture for an unrecorded request, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package pipedrive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Entities stored in a Snapshot
+const (
+	SnapshotDeals              = "deals"
+	SnapshotPersons            = "persons"
+	SnapshotOrganizations      = "organizations"
+	SnapshotActivities         = "activities"
+	SnapshotStages             = "stages"
+	SnapshotPipelines          = "pipelines"
+	SnapshotDealFields         = "dealFields"
+	SnapshotPersonFields       = "personFields"
+	SnapshotOrganizationFields = "organizationFields"
+
+	// SnapshotDealUpdates holds the updates of each deal as {"id": <deal id>, "data": [...]}
+	SnapshotDealUpdates = "dealUpdates"
+)
+
+// Records holds the raw JSON objects of an entity by their ID
+type Records map[int]json.RawMessage
+
+// Snapshot is a local copy of Pipedrive data stored as one JSON Lines file per entity
+type Snapshot struct {
+	dir string
+}
+
+// OpenSnapshot opens the snapshot in the given directory for writing, creating the directory if needed
+func OpenSnapshot(dir string) (*Snapshot, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{dir: dir}, nil
+}
+
+// ReadSnapshot opens an existing snapshot for reading. It fails if the directory
+// does not contain the deals of a completed sync, e.g. because of a mistyped path.
+func ReadSnapshot(dir string) (*Snapshot, error) {
+	s := &Snapshot{dir: dir}
+	_, err := os.Stat(s.path(SnapshotDeals))
+	if err != nil {
+		return nil, fmt.Errorf("no snapshot in %s: %v", dir, err)
+	}
+	return s, nil
+}
+
+// Dir returns the directory of the snapshot
+func (s *Snapshot) Dir() string {
+	return s.dir
+}
+
+// Has reports whether the snapshot contains the given entity
+func (s *Snapshot) Has(entity string) bool {
+	_, err := os.Stat(s.path(entity))
+	return err == nil
+}
+
+func (s *Snapshot) path(entity string) string {
+	return filepath.Join(s.dir, entity+".jsonl")
+}
+
+// Records reads all objects of the given entity. A missing entity yields no records.
+func (s *Snapshot) Records(entity string) (Records, error) {
+	recs := make(Records)
+	f, err := os.Open(s.path(entity))
+	if os.IsNotExist(err) {
+		return recs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		id, err := recordID(line)
+		if err != nil {
+			return nil, err
+		}
+		recs[id] = append(json.RawMessage{}, line...)
+	}
+	return recs, scanner.Err()
+}
+
+// WriteRecords replaces all objects of the given entity, ordered by ID
+func (s *Snapshot) WriteRecords(entity string, recs Records) error {
+	ids := make([]int, 0, len(recs))
+	for id := range recs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	tmp, err := ioutil.TempFile(s.dir, "."+entity)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	buf := &bytes.Buffer{}
+	for _, id := range ids {
+		// records must not span lines, API responses may be indented
+		buf.Reset()
+		err = json.Compact(buf, recs[id])
+		if err != nil {
+			err = fmt.Errorf("%s %d: %v", entity, id, err)
+			break
+		}
+		buf.WriteByte('\n')
+		_, err = w.Write(buf.Bytes())
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	err2 := tmp.Close()
+	if err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(entity))
+}
+
+// recordID extracts the id of a raw object
+func recordID(data []byte) (int, error) {
+	var obj struct {
+		ID int `json:"id"`
+	}
+	err := json.Unmarshal(data, &obj)
+	return obj.ID, err
+}
+
+// decode unmarshals each record of the entity, ordered by ID, using the given function
+func (s *Snapshot) decode(entity string, each func(data json.RawMessage) error) error {
+	recs, err := s.Records(entity)
+	if err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(recs))
+	for id := range recs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		err = each(recs[id])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deals returns the deals of the snapshot. Custom fields are not mapped, use FromSnapshot for that.
+func (s *Snapshot) Deals() (DealRefs, error) {
+	deals := DealRefs{}
+	err := s.decode(SnapshotDeals, func(data json.RawMessage) error {
+		var d DealRef
+		err := json.Unmarshal(data, &d)
+		deals = append(deals, d)
+		return err
+	})
+	return deals, err
+}
+
+// Persons returns the persons of the snapshot
+func (s *Snapshot) Persons() ([]Person, error) {
+	persons := []Person{}
+	err := s.decode(SnapshotPersons, func(data json.RawMessage) error {
+		var p Person
+		err := json.Unmarshal(data, &p)
+		persons = append(persons, p)
+		return err
+	})
+	return persons, err
+}
+
+// Organizations returns the organizations of the snapshot
+func (s *Snapshot) Organizations() ([]Organization, error) {
+	orgs := []Organization{}
+	err := s.decode(SnapshotOrganizations, func(data json.RawMessage) error {
+		var o Organization
+		err := json.Unmarshal(data, &o)
+		orgs = append(orgs, o)
+		return err
+	})
+	return orgs, err
+}
+
+// Activities returns the activities of the snapshot
+func (s *Snapshot) Activities() ([]Activity, error) {
+	activities := []Activity{}
+	err := s.decode(SnapshotActivities, func(data json.RawMessage) error {
+		var a Activity
+		err := json.Unmarshal(data, &a)
+		activities = append(activities, a)
+		return err
+	})
+	return activities, err
+}
+
+// Stages returns the stages of all pipelines of the snapshot
+func (s *Snapshot) Stages() (Stages, error) {
+	stages := Stages{}
+	err := s.decode(SnapshotStages, func(data json.RawMessage) error {
+		var st Stage
+		err := json.Unmarshal(data, &st)
+		stages = append(stages, st)
+		return err
+	})
+	return stages, err
+}
+
+// Pipelines returns the pipelines of the snapshot
+func (s *Snapshot) Pipelines() ([]Pipeline, error) {
+	pipelines := []Pipeline{}
+	err := s.decode(SnapshotPipelines, func(data json.RawMessage) error {
+		var p Pipeline
+		err := json.Unmarshal(data, &p)
+		pipelines = append(pipelines, p)
+		return err
+	})
+	return pipelines, err
+}
+
+// DealFields returns the deal fields of the snapshot
+func (s *Snapshot) DealFields() ([]DealField, error) {
+	fields := []DealField{}
+	err := s.decode(SnapshotDealFields, func(data json.RawMessage) error {
+		var f DealField
+		err := json.Unmarshal(data, &f)
+		fields = append(fields, f)
+		return err
+	})
+	return fields, err
+}
@@ -0,0 +1,192 @@
+package pipedrive
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SurvivalPoint is a step of a Kaplan-Meier curve
+type SurvivalPoint struct {
+	Time     time.Duration
+	AtRisk   int
+	Events   int
+	Censored int
+	Survival float64
+}
+
+// SurvivalCurve is a Kaplan-Meier estimate ordered by time
+type SurvivalCurve []SurvivalPoint
+
+// Median returns the first time at which at most half of the deals are left.
+// The second return value is false if the curve never drops that far.
+func (c SurvivalCurve) Median() (time.Duration, bool) {
+	for _, p := range c {
+		if p.Survival <= 0.5 {
+			return p.Time, true
+		}
+	}
+	return 0, false
+}
+
+// WriteCSV exports the curve with the time given in days
+func (c SurvivalCurve) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"days", "at_risk", "events", "censored", "survival"})
+	if err != nil {
+		return err
+	}
+	for _, p := range c {
+		err = cw.Write([]string{
+			fmt.Sprintf("%.2f", p.Time.Hours()/24),
+			fmt.Sprintf("%d", p.AtRisk),
+			fmt.Sprintf("%d", p.Events),
+			fmt.Sprintf("%d", p.Censored),
+			fmt.Sprintf("%.4f", p.Survival),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type survivalObservation struct {
+	duration time.Duration
+	event    bool
+}
+
+func kaplanMeier(obs []survivalObservation) SurvivalCurve {
+	sort.SliceStable(obs, func(i, j int) bool {
+		return obs[i].duration < obs[j].duration
+	})
+
+	curve := SurvivalCurve{}
+	survival := 1.0
+	atRisk := len(obs)
+	for i := 0; i < len(obs); {
+		p := SurvivalPoint{
+			Time:   obs[i].duration,
+			AtRisk: atRisk,
+		}
+		for ; i < len(obs) && obs[i].duration == p.Time; i++ {
+			if obs[i].event {
+				p.Events++
+			} else {
+				p.Censored++
+			}
+		}
+		survival *= 1 - float64(p.Events)/float64(p.AtRisk)
+		p.Survival = survival
+		atRisk -= p.Events + p.Censored
+		curve = append(curve, p)
+	}
+	return curve
+}
+
+// TimeToClose holds the time-to-win and time-to-loss curves of a set of deals.
+// Open deals are censored at the evaluation time, deals closed the other way at their close time.
+type TimeToClose struct {
+	Deals int
+	Win   SurvivalCurve
+	Loss  SurvivalCurve
+}
+
+// TimeToCloseReport holds the overall estimate and one per stage of entry
+type TimeToCloseReport struct {
+	TimeToClose
+	ByEntryStage map[string]TimeToClose
+}
+
+// EntryStage returns the name of the stage the deal was created in
+func (cr PipelineChangeResult) EntryStage(stages Stages) string {
+	var first *DealUpdate
+	for i, u := range cr.Updates {
+		if u.StoryData.ActionType != "edit" || len(u.StoryData.ChangeLog) == 0 {
+			continue
+		}
+		if u.StoryData.ChangeLog[0].FieldName != "Phase" {
+			continue
+		}
+		if first == nil || u.StoryData.AddTime.Before(first.StoryData.AddTime.Time) {
+			first = &cr.Updates[i]
+		}
+	}
+	if first != nil {
+		if name, ok := first.StoryData.ChangeLog[0].OldValue.(string); ok {
+			return name
+		}
+	}
+
+	for _, s := range stages {
+		if s.Id == cr.Deal.Stage {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// closedAt returns when the deal was closed, or nil if it is still open
+func (d Deal) closedAt() *Time {
+	switch d.Status {
+	case "won":
+		return d.WonAt
+	case "lost":
+		return d.LostAt
+	}
+	return nil
+}
+
+// EstimateTimeToClose computes Kaplan-Meier estimates of the time from creation until a deal is won or lost
+func EstimateTimeToClose(results PipelineChangeResults, stages Stages, asOf time.Time) TimeToCloseReport {
+	type observations struct {
+		win, loss []survivalObservation
+	}
+	all := observations{}
+	byStage := map[string]*observations{}
+
+	for _, cr := range results {
+		end := asOf
+		if closed := cr.Deal.closedAt(); closed != nil {
+			end = closed.Time
+		}
+		d := end.Sub(cr.Deal.Added.Time)
+		if d < 0 {
+			continue
+		}
+		win := survivalObservation{duration: d, event: cr.Deal.Status == "won"}
+		loss := survivalObservation{duration: d, event: cr.Deal.Status == "lost"}
+
+		all.win = append(all.win, win)
+		all.loss = append(all.loss, loss)
+
+		entry := cr.EntryStage(stages)
+		o, e := byStage[entry]
+		if !e {
+			o = &observations{}
+			byStage[entry] = o
+		}
+		o.win = append(o.win, win)
+		o.loss = append(o.loss, loss)
+	}
+
+	report := TimeToCloseReport{
+		TimeToClose: TimeToClose{
+			Deals: len(all.win),
+			Win:   kaplanMeier(all.win),
+			Loss:  kaplanMeier(all.loss),
+		},
+		ByEntryStage: make(map[string]TimeToClose),
+	}
+	for stage, o := range byStage {
+		report.ByEntryStage[stage] = TimeToClose{
+			Deals: len(o.win),
+			Win:   kaplanMeier(o.win),
+			Loss:  kaplanMeier(o.loss),
+		}
+	}
+	return report
+}
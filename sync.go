@@ -0,0 +1,321 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sync mirrors Pipedrive data into a local Snapshot.
+// The first run loads everything, later runs apply the changes reported by the recents endpoint.
+type Sync struct {
+	api        *API
+	snapshot   *Snapshot
+	checkpoint CheckpointStore
+
+	records map[string]Records
+	dirty   map[string]bool
+	// pending is the checkpoint reached by the changes not yet written to the snapshot
+	pending *Checkpoint
+}
+
+// syncedItems maps the item types of the recents endpoint to snapshot entities
+var syncedItems = map[string]string{
+	"deal":         SnapshotDeals,
+	"person":       SnapshotPersons,
+	"organization": SnapshotOrganizations,
+	"activity":     SnapshotActivities,
+	"stage":        SnapshotStages,
+	"pipeline":     SnapshotPipelines,
+}
+
+// NewSync creates a Sync writing into the snapshot in the given directory
+func (pd *API) NewSync(dir string) (*Sync, error) {
+	snapshot, err := OpenSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Sync{
+		api:        pd,
+		snapshot:   snapshot,
+		checkpoint: FileCheckpoint(filepath.Join(dir, "state.json")),
+		records:    make(map[string]Records),
+		dirty:      make(map[string]bool),
+	}, nil
+}
+
+// Snapshot returns the snapshot written by the sync
+func (s *Sync) Snapshot() *Snapshot {
+	return s.snapshot
+}
+
+// Run does a full load if the snapshot has never been synced and an incremental sync otherwise
+func (s *Sync) Run() error {
+	cp, err := s.checkpoint.Load()
+	if err != nil {
+		return err
+	}
+	if cp.Since.IsZero() {
+		return s.FullLoad()
+	}
+	return s.Incremental()
+}
+
+// syncedList is a list endpoint mirrored into a snapshot entity
+type syncedList struct {
+	entity   string
+	endpoint string
+	values   url.Values
+}
+
+// FullLoad fetches all entities and replaces the snapshot.
+// Nothing is written unless every entity was fetched successfully.
+func (s *Sync) FullLoad() error {
+	started := time.Now()
+
+	pageSize := url.Values{"limit": {"500"}}
+	lists := []syncedList{
+		{SnapshotDeals, s.api.Endpoints.AllDeals, pageSize},
+		{SnapshotPersons, s.api.Endpoints.Persons, pageSize},
+		{SnapshotOrganizations, s.api.Endpoints.Organizations, pageSize},
+		{SnapshotActivities, s.api.Endpoints.Activities, url.Values{"limit": {"500"}, "user_id": {"0"}}},
+		{SnapshotPipelines, s.api.Endpoints.Pipelines, nil},
+		{SnapshotStages, strings.Split(s.api.Endpoints.Stages, "?")[0], nil},
+	}
+	loaded, err := s.fetch(append(lists, s.fieldLists()...))
+	if err != nil {
+		return err
+	}
+
+	// the pipeline change reports need the history of every deal
+	updates := make(Records)
+	for id := range loaded[SnapshotDeals] {
+		updates[id], err = s.fetchDealUpdates(id)
+		if err != nil {
+			return fmt.Errorf("loading updates of deal %d: %v", id, err)
+		}
+	}
+	loaded[SnapshotDealUpdates] = updates
+
+	err = s.write(loaded)
+	if err != nil {
+		return err
+	}
+
+	return s.checkpoint.Save(Checkpoint{Since: started.UTC().Truncate(time.Second)})
+}
+
+// Incremental applies the changes since the last sync
+func (s *Sync) Incremental() error {
+	items := make([]string, 0, len(syncedItems))
+	for item := range syncedItems {
+		items = append(items, item)
+	}
+
+	w := s.api.NewWatcher(syncCheckpoint{s}, items...)
+	err := w.Poll(s.apply)
+	// keep the changes applied before a failure, they are written together with their checkpoint
+	cerr := s.commit()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	loaded, err := s.fetch(s.fieldLists())
+	if err != nil {
+		return err
+	}
+	return s.write(loaded)
+}
+
+// fieldLists are the field definitions, which are not reported as recent changes and refetched on every run
+func (s *Sync) fieldLists() []syncedList {
+	return []syncedList{
+		{SnapshotDealFields, s.api.Endpoints.DealFields, nil},
+		{SnapshotPersonFields, s.api.Endpoints.PersonFields, nil},
+		{SnapshotOrganizationFields, s.api.Endpoints.OrganizationFields, nil},
+	}
+}
+
+// fetch loads the given lists, failing if any of them fails
+func (s *Sync) fetch(lists []syncedList) (map[string]Records, error) {
+	loaded := make(map[string]Records)
+	for _, l := range lists {
+		recs, err := s.fetchAll(l.endpoint, l.values)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %v", l.entity, err)
+		}
+		loaded[l.entity] = recs
+	}
+	return loaded, nil
+}
+
+// write replaces the given entities of the snapshot
+func (s *Sync) write(loaded map[string]Records) error {
+	for entity, recs := range loaded {
+		err := s.snapshot.WriteRecords(entity, recs)
+		if err != nil {
+			return err
+		}
+		delete(s.records, entity)
+	}
+	return nil
+}
+
+// fetchAll collects the raw objects of a list endpoint
+func (s *Sync) fetchAll(endpoint string, values url.Values) (Records, error) {
+	recs := make(Records)
+	err := s.api.getAllJSON(endpoint, values, func(data json.RawMessage) error {
+		var page []json.RawMessage
+		err := json.Unmarshal(data, &page)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page {
+			id, err := recordID(obj)
+			if err != nil {
+				return err
+			}
+			recs[id] = obj
+		}
+		return nil
+	})
+	return recs, err
+}
+
+// fetchDealUpdates returns the updates of a deal as record of SnapshotDealUpdates
+func (s *Sync) fetchDealUpdates(dealID int) (json.RawMessage, error) {
+	endpoint := strings.Split(fmt.Sprintf(s.api.Endpoints.Deals, dealID, 0), "?")[0]
+	updates := []json.RawMessage{}
+	err := s.api.getAllJSON(endpoint, nil, func(data json.RawMessage) error {
+		var page []json.RawMessage
+		err := json.Unmarshal(data, &page)
+		updates = append(updates, page...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		ID   int               `json:"id"`
+		Data []json.RawMessage `json:"data"`
+	}{dealID, updates})
+}
+
+// apply upserts a changed object into the snapshot records
+func (s *Sync) apply(ev ChangeEvent) error {
+	entity, e := syncedItems[ev.Item]
+	if !e {
+		return nil
+	}
+	recs, err := s.entity(entity)
+	if err != nil {
+		return err
+	}
+	deleted, err := deletedRecord(ev.Data)
+	if err != nil {
+		return fmt.Errorf("%s %d: %v", ev.Item, ev.ID, err)
+	}
+	if deleted {
+		delete(recs, ev.ID)
+	} else {
+		recs[ev.ID] = ev.Data
+	}
+	s.dirty[entity] = true
+
+	if entity != SnapshotDeals {
+		return nil
+	}
+	updates, err := s.entity(SnapshotDealUpdates)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		delete(updates, ev.ID)
+	} else {
+		updates[ev.ID], err = s.fetchDealUpdates(ev.ID)
+		if err != nil {
+			return err
+		}
+	}
+	s.dirty[SnapshotDealUpdates] = true
+	return nil
+}
+
+// deletedRecord reports whether a change removes the object: either no data was sent,
+// or the object is marked as deleted or inactive
+func deletedRecord(data json.RawMessage) (bool, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return true, nil
+	}
+	var obj struct {
+		Deleted    bool  `json:"deleted"`
+		ActiveFlag *bool `json:"active_flag"`
+	}
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return false, err
+	}
+	return obj.Deleted || (obj.ActiveFlag != nil && !*obj.ActiveFlag), nil
+}
+
+func (s *Sync) entity(entity string) (Records, error) {
+	if recs, e := s.records[entity]; e {
+		return recs, nil
+	}
+	recs, err := s.snapshot.Records(entity)
+	if err != nil {
+		return nil, err
+	}
+	s.records[entity] = recs
+	return recs, nil
+}
+
+// flush writes the changed entities to disk
+func (s *Sync) flush() error {
+	for entity := range s.dirty {
+		err := s.snapshot.WriteRecords(entity, s.records[entity])
+		if err != nil {
+			return err
+		}
+		delete(s.dirty, entity)
+	}
+	return nil
+}
+
+// commit writes the pending changes to the snapshot, then saves the checkpoint they reached
+func (s *Sync) commit() error {
+	err := s.flush()
+	if err != nil || s.pending == nil {
+		return err
+	}
+	err = s.checkpoint.Save(*s.pending)
+	if err != nil {
+		return err
+	}
+	s.pending = nil
+	return nil
+}
+
+// syncCheckpoint holds back the checkpoint of the watcher until the changes are committed,
+// instead of rewriting the snapshot after every change
+type syncCheckpoint struct {
+	s *Sync
+}
+
+func (c syncCheckpoint) Load() (Checkpoint, error) {
+	if c.s.pending != nil {
+		return *c.s.pending, nil
+	}
+	return c.s.checkpoint.Load()
+}
+
+func (c syncCheckpoint) Save(cp Checkpoint) error {
+	c.s.pending = &cp
+	return nil
+}
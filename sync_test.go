@@ -0,0 +1,117 @@
+package pipedrive_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/pipedrivetest"
+)
+
+func TestSyncFullLoadThenIncremental(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+	seed := func(collection string, objects ...pipedrivetest.Object) {
+		err := srv.Seed(collection, objects...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	seed(pipedrivetest.Pipelines, pipedrivetest.Object{"id": 1, "name": "Vertrieb"})
+	seed(pipedrivetest.Stages, pipedrivetest.Object{"id": 1, "name": "Lead", "pipeline_id": 1})
+	seed(pipedrivetest.Deals,
+		pipedrivetest.Object{"id": 1, "title": "First", "stage_id": 1},
+		pipedrivetest.Object{"id": 2, "title": "Second", "stage_id": 1},
+	)
+	seed(pipedrivetest.Persons, pipedrivetest.Object{"id": 5, "name": "Person"})
+	seed(pipedrivetest.Activities, pipedrivetest.Object{"id": 9, "subject": "Call", "deal_id": 1})
+
+	pd, err := pipedrive.NewAPI(srv.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sync, err := pd.NewSync(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := sync.Snapshot()
+	deals, err := snapshot.Deals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deals) != 2 {
+		t.Fatalf("expected 2 deals after the full load, got %d", len(deals))
+	}
+
+	seed(pipedrivetest.Deals,
+		pipedrivetest.Object{"id": 1, "title": "Renamed", "stage_id": 1},
+		pipedrivetest.Object{"id": 3, "title": "Third", "stage_id": 1},
+	)
+	seed(pipedrivetest.Persons, pipedrivetest.Object{"id": 5, "name": "Person", "active_flag": false})
+	err = pd.DeleteActivity(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deals, err = snapshot.Deals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := map[int]string{}
+	for _, d := range deals {
+		titles[d.ID] = d.Title
+	}
+	if len(titles) != 3 || titles[1] != "Renamed" || titles[2] != "Second" || titles[3] != "Third" {
+		t.Errorf("unexpected deals after the incremental run: %v", titles)
+	}
+
+	updates, err := snapshot.Records(pipedrive.SnapshotDealUpdates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, e := updates[3]; !e {
+		t.Errorf("expected the updates of the new deal 3")
+	}
+
+	persons, err := snapshot.Persons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(persons) != 0 {
+		t.Errorf("expected the inactive person to be removed, got %+v", persons)
+	}
+	activities, err := snapshot.Activities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 0 {
+		t.Errorf("expected the deleted activity to be removed, got %+v", activities)
+	}
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, pipedrive.SnapshotDeals+".jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(buf, []byte("\n")); n != 3 {
+		t.Errorf("expected one line per deal, got %d lines", n)
+	}
+}
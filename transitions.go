@@ -0,0 +1,221 @@
+package pipedrive
+
+import (
+	"math"
+	"sort"
+)
+
+// Absorbing states of a TransitionMatrix, next to the stage names
+const (
+	StateWon  = "won"
+	StateLost = "lost"
+)
+
+// TransitionMatrix counts the observed stage-to-stage transitions of a set of deals.
+// Closed deals additionally transition from their last stage into StateWon or StateLost.
+type TransitionMatrix struct {
+	States []string
+	Counts map[string]map[string]int
+}
+
+// sortedUpdates returns a copy of the pipeline updates ordered by time
+func (cr PipelineChangeResult) sortedUpdates() DealFlowUpdates {
+	updates := make(DealFlowUpdates, len(cr.PipelineUpdates))
+	copy(updates, cr.PipelineUpdates)
+	sort.Stable(updates)
+	return updates
+}
+
+// Phases returns the stages the deal passed through in chronological order
+func (cr PipelineChangeResult) Phases() []string {
+	phases := []string{}
+	for _, u := range cr.sortedUpdates() {
+		if len(phases) > 0 && phases[len(phases)-1] == u.Phase {
+			continue
+		}
+		phases = append(phases, u.Phase)
+	}
+	return phases
+}
+
+// sortedStages returns a copy of the stages ordered by their position in the pipeline
+func sortedStages(stages Stages) Stages {
+	sorted := make(Stages, len(stages))
+	copy(sorted, stages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OrderNr < sorted[j].OrderNr
+	})
+	return sorted
+}
+
+// NewTransitionMatrix builds the transition matrix from the given pipeline changes
+func NewTransitionMatrix(results PipelineChangeResults, stages Stages) TransitionMatrix {
+	m := TransitionMatrix{
+		Counts: make(map[string]map[string]int),
+	}
+	known := make(map[string]bool)
+	addState := func(s string) {
+		if known[s] {
+			return
+		}
+		known[s] = true
+		m.States = append(m.States, s)
+	}
+	for _, s := range sortedStages(stages) {
+		addState(s.Name)
+	}
+
+	for _, cr := range results {
+		phases := cr.Phases()
+		switch cr.Deal.Status {
+		case "won":
+			phases = append(phases, StateWon)
+		case "lost":
+			phases = append(phases, StateLost)
+		}
+		for i, phase := range phases {
+			if phase != StateWon && phase != StateLost {
+				addState(phase)
+			}
+			if i == 0 {
+				continue
+			}
+			m.add(phases[i-1], phase)
+		}
+	}
+
+	addState(StateWon)
+	addState(StateLost)
+	return m
+}
+
+func (m TransitionMatrix) add(from, to string) {
+	row, e := m.Counts[from]
+	if !e {
+		row = make(map[string]int)
+		m.Counts[from] = row
+	}
+	row[to]++
+}
+
+// Total returns the number of transitions observed out of the given state
+func (m TransitionMatrix) Total(from string) int {
+	total := 0
+	for _, c := range m.Counts[from] {
+		total += c
+	}
+	return total
+}
+
+// Probability returns the empirical probability of moving from one state to another
+func (m TransitionMatrix) Probability(from, to string) float64 {
+	total := m.Total(from)
+	if total == 0 {
+		return 0
+	}
+	return float64(m.Counts[from][to]) / float64(total)
+}
+
+// states returns States followed by any other state of Counts in sorted order
+func (m TransitionMatrix) states() []string {
+	known := make(map[string]bool)
+	states := append([]string{}, m.States...)
+	for _, s := range states {
+		known[s] = true
+	}
+	extra := []string{}
+	add := func(s string) {
+		if !known[s] {
+			known[s] = true
+			extra = append(extra, s)
+		}
+	}
+	for from, row := range m.Counts {
+		add(from)
+		for to := range row {
+			add(to)
+		}
+	}
+	sort.Strings(extra)
+	return append(states, extra...)
+}
+
+// WinProbabilities returns for each state the probability of eventually reaching StateWon.
+// States without any observed outgoing transition are omitted.
+func (m TransitionMatrix) WinProbabilities() map[string]float64 {
+	p := map[string]float64{
+		StateWon:  1,
+		StateLost: 0,
+	}
+
+	// iterate in a fixed order, the result of the in-place updates depends on it
+	states := m.states()
+	for i := 0; i < 10000; i++ {
+		delta := 0.0
+		for _, from := range states {
+			if from == StateWon || from == StateLost || m.Total(from) == 0 {
+				continue
+			}
+			v := 0.0
+			for _, to := range states {
+				v += m.Probability(from, to) * p[to]
+			}
+			delta = math.Max(delta, math.Abs(v-p[from]))
+			p[from] = v
+		}
+		if delta < 1e-12 {
+			break
+		}
+	}
+
+	return p
+}
+
+// StageProbability compares the configured deal probability of a stage with the empirical one
+type StageProbability struct {
+	Stage      Stage
+	Configured float64
+	Empirical  float64
+	Observed   int
+}
+
+// Deviation returns how far the empirical probability is above the configured one
+func (sp StageProbability) Deviation() float64 {
+	return sp.Empirical - sp.Configured
+}
+
+type StageProbabilities []StageProbability
+
+// CompareStageProbabilities matches the win probability of each stage against its DealProbability.
+// Stages without observed transitions are skipped.
+func (m TransitionMatrix) CompareStageProbabilities(stages Stages) StageProbabilities {
+	wp := m.WinProbabilities()
+	res := StageProbabilities{}
+	for _, s := range sortedStages(stages) {
+		p, e := wp[s.Name]
+		if !e {
+			continue
+		}
+		res = append(res, StageProbability{
+			Stage:      s,
+			Configured: float64(s.DealProbability) / 100,
+			Empirical:  p,
+			Observed:   m.Total(s.Name),
+		})
+	}
+	return res
+}
+
+// Outliers returns the stages whose deviation exceeds the given threshold, worst first
+func (sps StageProbabilities) Outliers(threshold float64) StageProbabilities {
+	res := StageProbabilities{}
+	for _, sp := range sps {
+		if math.Abs(sp.Deviation()) > threshold {
+			res = append(res, sp)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return math.Abs(res[i].Deviation()) > math.Abs(res[j].Deviation())
+	})
+	return res
+}
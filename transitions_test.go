@@ -0,0 +1,58 @@
+package pipedrive
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWinProbabilities(t *testing.T) {
+	stages := Stages{
+		{Id: 1, Name: "Lead", OrderNr: 1},
+		{Id: 2, Name: "Angebot", OrderNr: 2},
+	}
+	results := PipelineChangeResults{
+		{Deal: Deal{ID: 1, Status: "won"}, PipelineUpdates: phaseUpdates("Lead", "Angebot")},
+		{Deal: Deal{ID: 2, Status: "lost"}, PipelineUpdates: phaseUpdates("Lead")},
+		{Deal: Deal{ID: 3, Status: "won"}, PipelineUpdates: phaseUpdates("Angebot", "Lead", "Angebot")},
+	}
+
+	// Lead: 2x Angebot, 1x lost; Angebot: 2x won, 1x Lead
+	// p(Lead) = 2/3 p(Angebot), p(Angebot) = 2/3 + 1/3 p(Lead)
+	want := map[string]float64{
+		"Lead":    4.0 / 7,
+		"Angebot": 6.0 / 7,
+		StateWon:  1,
+		StateLost: 0,
+	}
+
+	m := NewTransitionMatrix(results, stages)
+	got := m.WinProbabilities()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d states, got %v", len(want), got)
+	}
+	for state, p := range want {
+		if math.Abs(got[state]-p) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", state, p, got[state])
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		again := NewTransitionMatrix(results, stages).WinProbabilities()
+		for state, p := range got {
+			if again[state] != p {
+				t.Fatalf("%s: not reproducible, %v != %v", state, again[state], p)
+			}
+		}
+	}
+}
+
+// phaseUpdates returns pipeline updates entering the given stages on consecutive days
+func phaseUpdates(names ...string) DealFlowUpdates {
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	updates := DealFlowUpdates{}
+	for i, name := range names {
+		updates = append(updates, DealFlowUpdate{Phase: name, PiT: NewTime(start.AddDate(0, 0, i))})
+	}
+	return updates
+}
@@ -0,0 +1,104 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// User models a Pipedrive user. Owners embedded in deals and organizations
+// only carry ID, Name, Email, Active, HasPicture, PicHash and Value.
+type User struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Email           string  `json:"email"`
+	Phone           string  `json:"phone"`
+	Active          bool    `json:"active_flag"`
+	Activated       bool    `json:"activated"`
+	IsAdmin         int     `json:"is_admin"`
+	RoleID          int     `json:"role_id"`
+	Locale          string  `json:"locale"`
+	DefaultCurrency string  `json:"default_currency"`
+	TimezoneName    string  `json:"timezone_name"`
+	IconURL         string  `json:"icon_url"`
+	IsYou           bool    `json:"is_you"`
+	LastLogin       *Time   `json:"last_login"`
+	Created         *Time   `json:"created"`
+	Modified        *Time   `json:"modified"`
+	HasPicture      int     `json:"has_pic"`
+	PicHash         *string `json:"pic_hash"`
+	Value           int     `json:"value"`
+}
+
+// FetchUsers returns all users of the company
+func (pd *API) FetchUsers() ([]User, error) {
+	var users []User
+	err := pd.getJSON(pd.Endpoints.Users, &users)
+	return users, err
+}
+
+// FetchUser returns the user with the given id
+func (pd *API) FetchUser(id int) (User, error) {
+	var u User
+	err := pd.getJSON(fmt.Sprintf(pd.Endpoints.User, id), &u)
+	return u, err
+}
+
+// FetchCurrentUser returns the user the API token belongs to
+func (pd *API) FetchCurrentUser() (User, error) {
+	var u User
+	err := pd.getJSON(pd.Endpoints.CurrentUser, &u)
+	return u, err
+}
+
+// FindUsers searches users by name or, if byEmail is set, by email address
+func (pd *API) FindUsers(term string, byEmail bool) ([]User, error) {
+	values := url.Values{}
+	values.Set("term", term)
+	if byEmail {
+		values.Set("search_by_email", "1")
+	}
+	var users []User
+	err := pd.getJSON(pd.Endpoints.UserFind+"?"+values.Encode(), &users)
+	return users, err
+}
+
+// FindUserByEmail returns the user with exactly the given email address
+func (pd *API) FindUserByEmail(email string) (User, error) {
+	users, err := pd.FindUsers(email, true)
+	if err != nil {
+		return User{}, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return User{}, fmt.Errorf("User with email '%s' not found", email)
+}
+
+// FindUserByName returns the user with exactly the given name
+func (pd *API) FindUserByName(name string) (User, error) {
+	users, err := pd.FindUsers(name, false)
+	if err != nil {
+		return User{}, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Name, name) {
+			return u, nil
+		}
+	}
+	return User{}, fmt.Errorf("User '%s' not found", name)
+}
+
+// UnmarshalJSON accepts a plain user id as well, as returned by some endpoints
+func (u *User) UnmarshalJSON(buf []byte) error {
+	var id int
+	if json.Unmarshal(buf, &id) == nil {
+		*u = User{ID: id, Value: id}
+		return nil
+	}
+	type user User
+	return json.Unmarshal(buf, (*user)(u))
+}
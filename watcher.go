@@ -0,0 +1,270 @@
+package pipedrive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recentsTimeFormat is the format of since_timestamp and the timestamps of the recents endpoint, in UTC
+const recentsTimeFormat = "2006-01-02 15:04:05"
+
+// DefaultWatchedItems are the item types polled by a Watcher if none are given
+var DefaultWatchedItems = []string{"deal", "person", "organization", "activity", "note"}
+
+// ChangeEvent is a changed object reported by the recents endpoint.
+// Depending on Item one of Deal, Person, Organization, Activity or Note is set.
+type ChangeEvent struct {
+	Item       string
+	ID         int
+	UpdateTime string
+	Data       json.RawMessage
+
+	Deal         *DealRef
+	Person       *Person
+	Organization *Organization
+	Activity     *Activity
+	Note         *Note
+}
+
+func (ev ChangeEvent) key() string {
+	return fmt.Sprintf("%s:%d:%s", ev.Item, ev.ID, ev.UpdateTime)
+}
+
+// Checkpoint is the position of a Watcher in the stream of changes
+type Checkpoint struct {
+	Since time.Time `json:"since"`
+	// Seen holds the changes at Since that were already emitted, as since_timestamp is inclusive
+	Seen []string `json:"seen"`
+}
+
+// CheckpointStore persists the checkpoint of a Watcher
+type CheckpointStore interface {
+	Load() (Checkpoint, error)
+	Save(Checkpoint) error
+}
+
+// FileCheckpoint stores the checkpoint as JSON in the given file
+func FileCheckpoint(path string) CheckpointStore {
+	return fileCheckpoint(path)
+}
+
+type fileCheckpoint string
+
+func (f fileCheckpoint) Load() (Checkpoint, error) {
+	var cp Checkpoint
+	buf, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, err
+	}
+	err = json.Unmarshal(buf, &cp)
+	return cp, err
+}
+
+func (f fileCheckpoint) Save(cp Checkpoint) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), ".checkpoint")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(buf)
+	err2 := tmp.Close()
+	if err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// Watcher polls the recents endpoint and emits the changed objects
+type Watcher struct {
+	api   *API
+	store CheckpointStore
+
+	// Items are the item types to watch, defaults to DefaultWatchedItems
+	Items []string
+	// Interval is the pause between two polls of Run, defaults to one minute
+	Interval time.Duration
+	// Start is used as first checkpoint if the store is empty, defaults to the time of the first poll
+	Start time.Time
+}
+
+// NewWatcher creates a Watcher persisting its position in the given store
+func (pd *API) NewWatcher(store CheckpointStore, items ...string) *Watcher {
+	if len(items) == 0 {
+		items = DefaultWatchedItems
+	}
+	return &Watcher{
+		api:      pd,
+		store:    store,
+		Items:    items,
+		Interval: time.Minute,
+	}
+}
+
+// Run polls until the context is canceled. Failed polls, e.g. due to network errors or a failing
+// callback, are logged and retried in the next interval from the last checkpoint.
+func (w *Watcher) Run(ctx context.Context, fn func(ChangeEvent) error) error {
+	for {
+		err := w.Poll(fn)
+		if err != nil {
+			logrus.Errorf("polling changes: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.Interval):
+		}
+	}
+}
+
+// Poll emits all changes since the last checkpoint. The checkpoint is saved after each change
+// that was handled successfully, so changes are neither lost nor repeated across restarts.
+// A change is emitted again only if the process stops between handling it and saving the checkpoint.
+func (w *Watcher) Poll(fn func(ChangeEvent) error) error {
+	cp, err := w.store.Load()
+	if err != nil {
+		return err
+	}
+	if cp.Since.IsZero() {
+		cp.Since = w.Start
+		if cp.Since.IsZero() {
+			cp.Since = time.Now()
+		}
+		cp.Since = cp.Since.UTC().Truncate(time.Second)
+		err = w.store.Save(cp)
+		if err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range cp.Seen {
+		seen[k] = true
+	}
+
+	values := url.Values{}
+	values.Set("since_timestamp", cp.Since.UTC().Format(recentsTimeFormat))
+	values.Set("items", strings.Join(w.Items, ","))
+	values.Set("limit", "500")
+
+	start := 0
+	for {
+		values.Set("start", strconv.Itoa(start))
+		res, err := w.api.getEndpoint(w.api.Endpoints.Recents + "?" + values.Encode())
+		if err != nil {
+			return err
+		}
+
+		var pres struct {
+			apiResult
+			Data []struct {
+				Item string          `json:"item"`
+				ID   int             `json:"id"`
+				Data json.RawMessage `json:"data"`
+			} `json:"data"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&pres)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !pres.Success {
+			return fmt.Errorf("Pipedrive request failed: %s", pres.Error)
+		}
+
+		for _, r := range pres.Data {
+			ev, err := w.decode(r.Item, r.ID, r.Data)
+			if err != nil {
+				return err
+			}
+			k := ev.key()
+			if seen[k] {
+				continue
+			}
+			err = fn(ev)
+			if err != nil {
+				return err
+			}
+
+			t, err := time.ParseInLocation(recentsTimeFormat, ev.UpdateTime, time.UTC)
+			switch {
+			case err == nil && t.After(cp.Since):
+				cp.Since = t
+				cp.Seen = []string{k}
+				seen = map[string]bool{k: true}
+			default:
+				// changes without a valid update time can't move Since, but must not be emitted again
+				cp.Seen = append(cp.Seen, k)
+				seen[k] = true
+			}
+			err = w.store.Save(cp)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !pres.AdditionalData.Pagination.MoreItemsInCollection {
+			return nil
+		}
+		start += pres.AdditionalData.Pagination.Limit
+	}
+}
+
+func (w *Watcher) decode(item string, id int, data json.RawMessage) (ChangeEvent, error) {
+	ev := ChangeEvent{
+		Item: item,
+		ID:   id,
+		Data: data,
+	}
+	var meta struct {
+		UpdateTime string `json:"update_time"`
+	}
+	if json.Unmarshal(data, &meta) == nil {
+		ev.UpdateTime = meta.UpdateTime
+	}
+
+	var err error
+	switch item {
+	case "deal":
+		var d DealRef
+		d, err = w.api.DecodeDeal(data)
+		ev.Deal = &d
+	case "person":
+		var p Person
+		p, err = w.api.DecodePerson(data)
+		ev.Person = &p
+	case "organization":
+		var o Organization
+		o, err = w.api.DecodeOrganization(data)
+		ev.Organization = &o
+	case "activity":
+		ev.Activity = &Activity{}
+		err = json.Unmarshal(data, ev.Activity)
+	case "note":
+		ev.Note = &Note{}
+		err = json.Unmarshal(data, ev.Note)
+	}
+	if err != nil {
+		return ev, fmt.Errorf("decoding %s %d: %v", item, id, err)
+	}
+	return ev, nil
+}
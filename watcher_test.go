@@ -0,0 +1,147 @@
+package pipedrive_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/pipedrivetest"
+)
+
+// memoryCheckpoint is a CheckpointStore keeping the checkpoint in memory
+type memoryCheckpoint struct {
+	cp pipedrive.Checkpoint
+}
+
+func (m *memoryCheckpoint) Load() (pipedrive.Checkpoint, error) {
+	return m.cp, nil
+}
+
+func (m *memoryCheckpoint) Save(cp pipedrive.Checkpoint) error {
+	m.cp = cp
+	return nil
+}
+
+// pollIDs polls once and returns the ids of the emitted deals
+func pollIDs(t *testing.T, w *pipedrive.Watcher) []int {
+	ids := []int{}
+	err := w.Poll(func(ev pipedrive.ChangeEvent) error {
+		ids = append(ids, ev.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestWatcherResumesFromCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+	err = srv.Seed(pipedrivetest.Deals,
+		pipedrivetest.Object{"id": 1, "update_time": "2019-03-01 10:00:00"},
+		pipedrivetest.Object{"id": 2, "update_time": "2019-03-01 11:00:00"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pd, err := pipedrive.NewAPI(srv.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := pipedrive.FileCheckpoint(filepath.Join(dir, "state.json"))
+	newWatcher := func() *pipedrive.Watcher {
+		w := pd.NewWatcher(store, "deal")
+		w.Start = time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+		return w
+	}
+
+	if ids := pollIDs(t, newWatcher()); !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("expected deals 1 and 2 on the first poll, got %v", ids)
+	}
+	if ids := pollIDs(t, newWatcher()); len(ids) != 0 {
+		t.Errorf("expected no changes after resuming, got %v", ids)
+	}
+
+	err = srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{"id": 3, "update_time": "2019-03-01 12:00:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := pollIDs(t, newWatcher()); !reflect.DeepEqual(ids, []int{3}) {
+		t.Errorf("expected only deal 3 after resuming, got %v", ids)
+	}
+
+	cp, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC); !cp.Since.Equal(want) {
+		t.Errorf("expected checkpoint at %s, got %s", want, cp.Since)
+	}
+}
+
+func TestWatcherDuplicateTimestampsAtSince(t *testing.T) {
+	srv := pipedrivetest.NewServer()
+	defer srv.Close()
+	const at = "2019-03-01 10:00:00"
+	err := srv.Seed(pipedrivetest.Deals,
+		pipedrivetest.Object{"id": 1, "update_time": at},
+		pipedrivetest.Object{"id": 2, "update_time": at},
+		pipedrivetest.Object{"id": 3, "update_time": at},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pd, err := pipedrive.NewAPI(srv.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryCheckpoint{}
+	w := pd.NewWatcher(store, "deal")
+	w.Start = time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// the callback fails for deal 2, the checkpoint keeps deal 1 as handled
+	failed := errors.New("failed")
+	ids := []int{}
+	err = w.Poll(func(ev pipedrive.ChangeEvent) error {
+		if ev.ID == 2 {
+			return failed
+		}
+		ids = append(ids, ev.ID)
+		return nil
+	})
+	if err != failed {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("expected deal 1 before the failure, got %v", ids)
+	}
+
+	if ids := pollIDs(t, w); !reflect.DeepEqual(ids, []int{2, 3}) {
+		t.Errorf("expected deals 2 and 3 after the failure, got %v", ids)
+	}
+
+	err = srv.Seed(pipedrivetest.Deals, pipedrivetest.Object{"id": 4, "update_time": at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := pollIDs(t, w); !reflect.DeepEqual(ids, []int{4}) {
+		t.Errorf("expected only the new deal 4 at the same timestamp, got %v", ids)
+	}
+	if len(store.cp.Seen) != 4 {
+		t.Errorf("expected all 4 changes at Since as seen, got %v", store.cp.Seen)
+	}
+}
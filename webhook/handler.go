@@ -0,0 +1,248 @@
+// Package webhook receives Pipedrive webhook calls and dispatches them as typed events.
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	pipedrive "github.com/vitraum/golang-pipedrive"
+)
+
+// maxBodySize limits the size of accepted webhook payloads
+const maxBodySize = 10 << 20
+
+// Meta carries the information about the change that triggered the webhook
+type Meta struct {
+	Action           string `json:"action"`
+	Object           string `json:"object"`
+	ID               int    `json:"id"`
+	CompanyID        int    `json:"company_id"`
+	UserID           int    `json:"user_id"`
+	Host             string `json:"host"`
+	Timestamp        int64  `json:"timestamp"`
+	PermittedUserIDs []int  `json:"permitted_user_ids"`
+	ChangeSource     string `json:"change_source"`
+	IsBulkUpdate     bool   `json:"is_bulk_update"`
+}
+
+// Event is a decoded webhook call.
+// Current and Previous hold a *pipedrive.DealRef, *pipedrive.Organization,
+// *pipedrive.Activity or *pipedrive.Person depending on the object,
+// json.RawMessage for other objects or nil if not sent.
+type Event struct {
+	// Name is the event like "added.deal" or "deleted.person"
+	Name     string
+	Meta     Meta
+	Retry    int
+	Current  interface{}
+	Previous interface{}
+
+	RawCurrent  json.RawMessage
+	RawPrevious json.RawMessage
+}
+
+// HandlerFunc is called for each dispatched event
+type HandlerFunc func(Event) error
+
+// Handler is an http.Handler receiving Pipedrive webhooks
+type Handler struct {
+	api      *pipedrive.API
+	user     string
+	password string
+
+	mu       sync.RWMutex
+	handlers map[string][]HandlerFunc
+}
+
+// Option represents an option given to the Handler constructor
+type Option func(*Handler) error
+
+// BasicAuth requires the webhook calls to carry the given credentials
+func BasicAuth(user, password string) Option {
+	return func(h *Handler) error {
+		if user == "" || password == "" {
+			return errors.New("basic auth user and password must not be empty")
+		}
+		h.user = user
+		h.password = password
+		return nil
+	}
+}
+
+// WithAPI decodes deals and organizations using the API, applying its custom field mapping
+func WithAPI(pd *pipedrive.API) Option {
+	return func(h *Handler) error {
+		h.api = pd
+		return nil
+	}
+}
+
+// NewHandler creates a new webhook receiver from the given options
+func NewHandler(options ...Option) (*Handler, error) {
+	h := &Handler{
+		handlers: make(map[string][]HandlerFunc),
+	}
+	for _, option := range options {
+		err := option(h)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if h.api == nil {
+		pd, err := pipedrive.NewAPI()
+		if err != nil {
+			return nil, err
+		}
+		h.api = pd
+	}
+	return h, nil
+}
+
+// On registers a callback for an event like "updated.deal".
+// Either part may be "*" to match all actions or objects.
+func (h *Handler) On(event string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[event] = append(h.handlers[event], fn)
+}
+
+func (h *Handler) handlersFor(action, object string) []HandlerFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	fns := []HandlerFunc{}
+	for _, name := range []string{action + "." + object, "*." + object, action + ".*", "*.*"} {
+		fns = append(fns, h.handlers[name]...)
+	}
+	return fns
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.user == "" {
+		return true
+	}
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+	return userOK && passwordOK
+}
+
+// ServeHTTP decodes the webhook call and dispatches it to the registered callbacks.
+// All callbacks are run, if any of them fails the call is answered with 500 so Pipedrive
+// retries it. A retry runs all callbacks again, so they have to be idempotent.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="pipedrive"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ev, err := h.Decode(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	failed := false
+	for _, fn := range h.handlersFor(ev.Meta.Action, ev.Meta.Object) {
+		err = fn(ev)
+		if err != nil {
+			logrus.Errorf("webhook %s for %d: %s", ev.Name, ev.Meta.ID, err)
+			failed = true
+		}
+	}
+	if failed {
+		http.Error(w, "handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Decode reads a webhook payload into an Event
+func (h *Handler) Decode(r io.Reader) (Event, error) {
+	var payload struct {
+		Event    string          `json:"event"`
+		Meta     Meta            `json:"meta"`
+		Retry    int             `json:"retry"`
+		Current  json.RawMessage `json:"current"`
+		Previous json.RawMessage `json:"previous"`
+	}
+	err := json.NewDecoder(r).Decode(&payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	if payload.Meta.Action == "" || payload.Meta.Object == "" {
+		parts := strings.SplitN(payload.Event, ".", 2)
+		if len(parts) != 2 {
+			return Event{}, fmt.Errorf("invalid event '%s'", payload.Event)
+		}
+		payload.Meta.Action, payload.Meta.Object = parts[0], parts[1]
+	}
+
+	ev := Event{
+		Name:        payload.Meta.Action + "." + payload.Meta.Object,
+		Meta:        payload.Meta,
+		Retry:       payload.Retry,
+		RawCurrent:  payload.Current,
+		RawPrevious: payload.Previous,
+	}
+	ev.Current, err = h.decodeObject(payload.Meta.Object, payload.Current)
+	if err != nil {
+		return Event{}, err
+	}
+	ev.Previous, err = h.decodeObject(payload.Meta.Object, payload.Previous)
+	if err != nil {
+		return Event{}, err
+	}
+	return ev, nil
+}
+
+func (h *Handler) decodeObject(object string, data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	switch object {
+	case "deal":
+		d, err := h.api.DecodeDeal(data)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	case "organization":
+		o, err := h.api.DecodeOrganization(data)
+		if err != nil {
+			return nil, err
+		}
+		return &o, nil
+	case "person":
+		p, err := h.api.DecodePerson(data)
+		if err != nil {
+			return nil, err
+		}
+		return &p, nil
+	case "activity":
+		var a pipedrive.Activity
+		err := json.Unmarshal(data, &a)
+		if err != nil {
+			return nil, err
+		}
+		return &a, nil
+	}
+	return data, nil
+}
@@ -0,0 +1,171 @@
+package webhook_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	pipedrive "github.com/vitraum/golang-pipedrive"
+	"github.com/vitraum/golang-pipedrive/webhook"
+)
+
+const updatedDeal = `{
+	"event": "updated.deal",
+	"meta": {"action": "updated", "object": "deal", "id": 7},
+	"current": {"id": 7, "title": "Deal", "value": 200, "stage_id": 2, "status": "open", "add_time": "2019-03-01 10:00:00", "user_id": 3},
+	"previous": {"id": 7, "title": "Deal", "value": 100, "stage_id": 1, "status": "open", "add_time": "2019-03-01 10:00:00", "user_id": 3}
+}`
+
+const addedActivity = `{
+	"event": "added.activity",
+	"meta": {"action": "added", "object": "activity", "id": 9},
+	"current": {"id": 9, "subject": "Call", "deal_id": 7, "done": false},
+	"previous": null
+}`
+
+func newHandler(t *testing.T, options ...webhook.Option) *webhook.Handler {
+	pd, err := pipedrive.NewAPI(pipedrive.FixedToken("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := webhook.NewHandler(append(options, webhook.WithAPI(pd))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func post(h http.Handler, body string, auth func(*http.Request)) int {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if auth != nil {
+		auth(r)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestHandlerRejectsUnauthorized(t *testing.T) {
+	h := newHandler(t, webhook.BasicAuth("pipedrive", "secret"))
+	calls := 0
+	h.On("*.*", func(webhook.Event) error {
+		calls++
+		return nil
+	})
+
+	tests := []struct {
+		name string
+		auth func(*http.Request)
+		code int
+	}{
+		{"without credentials", nil, http.StatusUnauthorized},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("pipedrive", "guess") }, http.StatusUnauthorized},
+		{"wrong user", func(r *http.Request) { r.SetBasicAuth("admin", "secret") }, http.StatusUnauthorized},
+		{"valid credentials", func(r *http.Request) { r.SetBasicAuth("pipedrive", "secret") }, http.StatusOK},
+	}
+	for _, test := range tests {
+		code := post(h, updatedDeal, test.auth)
+		if code != test.code {
+			t.Errorf("%s: expected %d, got %d", test.name, test.code, code)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the callback to run only for the authorized call, ran %d times", calls)
+	}
+}
+
+func TestHandlerMatchesWildcards(t *testing.T) {
+	h := newHandler(t)
+	called := []string{}
+	for _, pattern := range []string{"updated.deal", "*.deal", "updated.*", "*.*", "added.deal", "updated.person"} {
+		pattern := pattern
+		h.On(pattern, func(webhook.Event) error {
+			called = append(called, pattern)
+			return nil
+		})
+	}
+
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{updatedDeal, []string{"*.*", "*.deal", "updated.*", "updated.deal"}},
+		{addedActivity, []string{"*.*"}},
+	}
+	for _, test := range tests {
+		called = called[:0]
+		code := post(h, test.body, nil)
+		if code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+		sort.Strings(called)
+		if strings.Join(called, " ") != strings.Join(test.want, " ") {
+			t.Errorf("expected %v to be called, got %v", test.want, called)
+		}
+	}
+}
+
+func TestHandlerDecodesObjects(t *testing.T) {
+	h := newHandler(t)
+
+	ev, err := h.Decode(strings.NewReader(updatedDeal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Name != "updated.deal" || ev.Meta.ID != 7 {
+		t.Errorf("unexpected event %s for %d", ev.Name, ev.Meta.ID)
+	}
+	current, ok := ev.Current.(*pipedrive.DealRef)
+	if !ok {
+		t.Fatalf("expected current to be a *pipedrive.DealRef, got %T", ev.Current)
+	}
+	previous, ok := ev.Previous.(*pipedrive.DealRef)
+	if !ok {
+		t.Fatalf("expected previous to be a *pipedrive.DealRef, got %T", ev.Previous)
+	}
+	if current.Value != 200 || current.Stage != 2 || current.User.ID != 3 {
+		t.Errorf("unexpected current deal %+v", current)
+	}
+	if previous.Value != 100 || previous.Stage != 1 {
+		t.Errorf("unexpected previous deal %+v", previous)
+	}
+
+	ev, err = h.Decode(strings.NewReader(addedActivity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity, ok := ev.Current.(*pipedrive.Activity)
+	if !ok {
+		t.Fatalf("expected current to be a *pipedrive.Activity, got %T", ev.Current)
+	}
+	if activity.Subject != "Call" || activity.DealID != 7 {
+		t.Errorf("unexpected activity %+v", activity)
+	}
+	if ev.Previous != nil {
+		t.Errorf("expected no previous object, got %v", ev.Previous)
+	}
+}
+
+func TestHandlerRunsAllCallbacks(t *testing.T) {
+	h := newHandler(t)
+	calls := 0
+	h.On("updated.deal", func(webhook.Event) error {
+		calls++
+		return errors.New("failed")
+	})
+	h.On("*.deal", func(webhook.Event) error {
+		calls++
+		return nil
+	})
+
+	code := post(h, updatedDeal, nil)
+	if code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", code)
+	}
+	if calls != 2 {
+		t.Errorf("expected both callbacks to run, ran %d", calls)
+	}
+}
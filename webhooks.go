@@ -0,0 +1,147 @@
+package pipedrive
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Webhook models a webhook subscription
+type Webhook struct {
+	ID               int    `json:"id"`
+	CompanyID        int    `json:"company_id"`
+	OwnerID          int    `json:"owner_id"`
+	UserID           int    `json:"user_id"`
+	EventAction      string `json:"event_action"`
+	EventObject      string `json:"event_object"`
+	SubscriptionURL  string `json:"subscription_url"`
+	HTTPAuthUser     string `json:"http_auth_user"`
+	IsActive         int    `json:"is_active"`
+	Type             string `json:"type"`
+	AddTime          *Time  `json:"add_time"`
+	LastDeliveryTime *Time  `json:"last_delivery_time"`
+	LastHTTPStatus   int    `json:"last_http_status"`
+}
+
+// WebhookSpec describes a desired webhook subscription
+type WebhookSpec struct {
+	EventAction      string `json:"event_action"`
+	EventObject      string `json:"event_object"`
+	SubscriptionURL  string `json:"subscription_url"`
+	HTTPAuthUser     string `json:"http_auth_user,omitempty"`
+	HTTPAuthPassword string `json:"http_auth_password,omitempty"`
+	UserID           int    `json:"user_id,omitempty"`
+}
+
+func (s WebhookSpec) String() string {
+	return fmt.Sprintf("%s.%s -> %s", s.EventAction, s.EventObject, s.SubscriptionURL)
+}
+
+// Spec returns the subscription details of the webhook. The password is not returned by the API.
+func (w Webhook) Spec() WebhookSpec {
+	return WebhookSpec{
+		EventAction:     w.EventAction,
+		EventObject:     w.EventObject,
+		SubscriptionURL: w.SubscriptionURL,
+		HTTPAuthUser:    w.HTTPAuthUser,
+		UserID:          w.UserID,
+	}
+}
+
+// key identifies a subscription regardless of its password and user
+func (s WebhookSpec) key() string {
+	return strings.Join([]string{s.EventAction, s.EventObject, s.SubscriptionURL, s.HTTPAuthUser}, "\x00")
+}
+
+// FetchWebhooks returns all webhook subscriptions of the company
+func (pd *API) FetchWebhooks() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := pd.getJSON(pd.Endpoints.Webhooks, &webhooks)
+	return webhooks, err
+}
+
+// CreateWebhook adds a new webhook subscription
+func (pd *API) CreateWebhook(spec WebhookSpec) (Webhook, error) {
+	var w Webhook
+	err := pd.sendJSON(pd.postEndpoint, pd.Endpoints.Webhooks, spec, &w)
+	return w, err
+}
+
+// DeleteWebhook removes the webhook subscription with the given id
+func (pd *API) DeleteWebhook(id int) error {
+	return pd.sendJSON(pd.deleteEndpoint, fmt.Sprintf(pd.Endpoints.Webhook, id), nil, nil)
+}
+
+// WebhookPlan lists the changes needed to get from the existing to the desired subscriptions
+type WebhookPlan struct {
+	Create []WebhookSpec
+	Delete []Webhook
+	Keep   []Webhook
+}
+
+// PlanWebhooks compares the existing subscriptions with the desired ones.
+// Subscriptions are matched by action, object, URL and auth user; duplicates are deleted.
+func PlanWebhooks(existing []Webhook, desired []WebhookSpec) WebhookPlan {
+	plan := WebhookPlan{}
+	wanted := make(map[string]bool)
+	for _, s := range desired {
+		wanted[s.key()] = true
+	}
+
+	present := make(map[string]bool)
+	for _, w := range existing {
+		k := w.Spec().key()
+		if wanted[k] && !present[k] {
+			present[k] = true
+			plan.Keep = append(plan.Keep, w)
+			continue
+		}
+		plan.Delete = append(plan.Delete, w)
+	}
+
+	for _, s := range desired {
+		k := s.key()
+		if present[k] {
+			continue
+		}
+		present[k] = true
+		plan.Create = append(plan.Create, s)
+	}
+	return plan
+}
+
+// Empty reports whether the plan has no changes
+func (p WebhookPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Delete) == 0
+}
+
+// String renders the plan as diff, one subscription per line
+func (p WebhookPlan) String() string {
+	b := &strings.Builder{}
+	for _, w := range p.Delete {
+		fmt.Fprintf(b, "- %s (id %d)\n", w.Spec(), w.ID)
+	}
+	for _, s := range p.Create {
+		fmt.Fprintf(b, "+ %s\n", s)
+	}
+	for _, w := range p.Keep {
+		fmt.Fprintf(b, "  %s (id %d)\n", w.Spec(), w.ID)
+	}
+	return b.String()
+}
+
+// ApplyWebhookPlan creates the missing subscriptions before deleting the obsolete ones
+func (pd *API) ApplyWebhookPlan(plan WebhookPlan) error {
+	for _, s := range plan.Create {
+		_, err := pd.CreateWebhook(s)
+		if err != nil {
+			return fmt.Errorf("creating %s: %v", s, err)
+		}
+	}
+	for _, w := range plan.Delete {
+		err := pd.DeleteWebhook(w.ID)
+		if err != nil {
+			return fmt.Errorf("deleting %s: %v", w.Spec(), err)
+		}
+	}
+	return nil
+}